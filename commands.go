@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rbarcante/conductor-powerline/internal/claudehome"
+	"github.com/rbarcante/conductor-powerline/internal/config"
+)
+
+// command is a subcommand handler. Unlike the statusline itself, subcommands
+// are run by people, so they report errors on stderr and exit non-zero.
+type command func(args []string, stdout, stderr io.Writer) error
+
+// commands maps subcommand names to their handlers.
+var commands = map[string]command{
+	"allow":  allowCommand,
+	"config": configCommand,
+	"replay": replayCommand,
+}
+
+// runCommand dispatches args[0] to its subcommand and returns the exit code.
+func runCommand(args []string, stdout, stderr io.Writer) int {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		_, _ = fmt.Fprintf(stderr, "conductor-powerline: unknown command %q\n", args[0])
+		return 2
+	}
+	if err := cmd(args[1:], stdout, stderr); err != nil {
+		_, _ = fmt.Fprintf(stderr, "conductor-powerline %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// configSubcommands maps "config <name>" handlers.
+var configSubcommands = map[string]command{
+	"schema":   configSchemaCommand,
+	"validate": configValidateCommand,
+}
+
+func configCommand(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing subcommand (available: %s)", strings.Join(sortedKeys(configSubcommands), ", "))
+	}
+	sub, ok := configSubcommands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown subcommand %q (available: %s)", args[0], strings.Join(sortedKeys(configSubcommands), ", "))
+	}
+	return sub(args[1:], stdout, stderr)
+}
+
+// configSchemaCommand prints the JSON Schema for the config file.
+func configSchemaCommand(_ []string, stdout, _ io.Writer) error {
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(config.Schema())
+}
+
+// configValidateCommand loads the config the statusline would use in the
+// given directory (default: the current one) and lists every problem found.
+func configValidateCommand(args []string, stdout, _ io.Writer) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: config validate [dir]")
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	_, diags := loadConfig(dir, dir, "", config.Config{}, nil)
+	for _, d := range diags {
+		_, _ = fmt.Fprintln(stdout, d)
+	}
+	if len(diags) > 0 {
+		return fmt.Errorf("%d problem(s) found", len(diags))
+	}
+	_, _ = fmt.Fprintln(stdout, "no problems found")
+	return nil
+}
+
+// allowCommand trusts the project config of a workspace (default: the
+// current directory) so it may set keys beyond projectKeys. Trust is pinned
+// to the files' current contents; editing them requires allowing again.
+func allowCommand(args []string, stdout, _ io.Writer) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: allow [dir]")
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		if dir, err = filepath.Abs(args[0]); err != nil {
+			return err
+		}
+	}
+
+	files := config.DiscoverProjectFiles(dir)
+	if len(files) == 0 {
+		return fmt.Errorf("no project config found in %s or its parents up to the git root", dir)
+	}
+	claudeDir := claudehome.Dir()
+	if claudeDir == "" {
+		return fmt.Errorf("cannot determine the Claude config directory")
+	}
+	path := filepath.Join(claudeDir, config.TrustFileName)
+	store, err := config.LoadTrust(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	if err := store.Allow(dir, files); err != nil {
+		return err
+	}
+	if err := store.Save(path); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(stdout, "trusted %s:\n", dir)
+	for _, f := range config.TrustedFiles(files) {
+		_, _ = fmt.Fprintf(stdout, "  %s\n", f)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]command) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,66 @@
+// Package atomicfile writes the caches and state files shared between
+// statusline renders, so a concurrent render never reads a partial file, and
+// prunes the ones no longer in use.
+package atomicfile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Write replaces path with data, readable only by the user. The data goes to
+// a temporary file in the same directory, which is then renamed over path;
+// missing parent directories are created.
+func Write(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
+
+// WriteJSON marshals v and writes it to path with Write.
+func WriteJSON(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return Write(path, data)
+}
+
+// Prune removes the files directly in dir that were last modified more than
+// maxAge ago, including temporary files left by an interrupted Write.
+// Subdirectories are left alone and errors are ignored: a file that is not
+// removed now is removed by a later call.
+func Prune(dir string, maxAge time.Duration) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-maxAge)
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		if info, err := e.Info(); err == nil && info.ModTime().Before(cutoff) {
+			_ = os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
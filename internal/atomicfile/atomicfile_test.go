@@ -0,0 +1,89 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestWriteJSONCreatesDirs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a", "b", "state.json")
+
+	if err := WriteJSON(path, map[string]int{"offset": 42}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"offset":42}` {
+		t.Errorf("unexpected contents %q", data)
+	}
+	if info, _ := os.Stat(path); runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestWriteReplacesWithoutLeftovers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	for _, content := range []string{"first", "second"} {
+		if err := Write(path, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "second" {
+		t.Errorf("expected the last write, got %q", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected no temporary files left, got %v", entries)
+	}
+}
+
+func TestWriteJSONUnmarshalable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := WriteJSON(path, func() {}); err == nil {
+		t.Error("expected an error for a value JSON cannot encode")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected nothing written")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+	for _, name := range []string{"stale.json", "stale.json.123.tmp", "fresh.json"} {
+		if err := Write(filepath.Join(dir, name), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"stale.json", "stale.json.123.tmp"} {
+		if err := os.Chtimes(filepath.Join(dir, name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "sub"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	Prune(dir, 24*time.Hour)
+
+	var names []string
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if len(names) != 2 || names[0] != "fresh.json" || names[1] != "sub" {
+		t.Errorf("expected only the fresh file and the directory left, got %v", names)
+	}
+
+	// A missing directory is not an error.
+	Prune(filepath.Join(dir, "missing"), time.Hour)
+}
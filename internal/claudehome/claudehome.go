@@ -0,0 +1,35 @@
+// Package claudehome resolves Claude Code's configuration directory, which
+// holds credentials, installed plugins and the user-level powerline config.
+package claudehome
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// EnvVar is the environment variable Claude Code reads to relocate its
+// configuration directory, e.g. to keep separate profiles side by side.
+const EnvVar = "CLAUDE_CONFIG_DIR"
+
+// Dir returns $CLAUDE_CONFIG_DIR when set, otherwise ~/.claude.
+// Returns "" when neither can be determined.
+func Dir() string {
+	if dir := os.Getenv(EnvVar); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".claude")
+}
+
+// Resolve returns the config directory for an explicit home directory,
+// home/.claude, or Dir() when home is empty. Callers that accept a home
+// override (mostly for tests) use this so the default path honors EnvVar.
+func Resolve(home string) string {
+	if home == "" {
+		return Dir()
+	}
+	return filepath.Join(home, ".claude")
+}
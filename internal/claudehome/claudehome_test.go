@@ -0,0 +1,39 @@
+package claudehome
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDirDefaultsToHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(EnvVar, "")
+
+	if got, want := Dir(), filepath.Join(home, ".claude"); got != want {
+		t.Errorf("Dir() = %q, want %q", got, want)
+	}
+}
+
+func TestDirHonorsEnv(t *testing.T) {
+	custom := filepath.Join(t.TempDir(), "claude-work")
+	t.Setenv(EnvVar, custom)
+
+	if got := Dir(); got != custom {
+		t.Errorf("Dir() = %q, want %q", got, custom)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	custom := filepath.Join(t.TempDir(), "claude-work")
+	t.Setenv(EnvVar, custom)
+
+	if got := Resolve(""); got != custom {
+		t.Errorf("Resolve(\"\") = %q, want %q", got, custom)
+	}
+	home := t.TempDir()
+	if got, want := Resolve(home), filepath.Join(home, ".claude"); got != want {
+		t.Errorf("Resolve(home) = %q, want %q", got, want)
+	}
+}
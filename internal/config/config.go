@@ -2,8 +2,14 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/rbarcante/conductor-powerline/internal/debug"
 )
 
 // DefaultConfig returns the default configuration with all segments enabled.
@@ -15,24 +21,48 @@ func DefaultConfig() Config {
 			CompactWidth: 100,
 		},
 		Segments: map[string]SegmentConfig{
-			"directory":          {Enabled: true},
-			"git":                {Enabled: true},
-			"model":              {Enabled: true},
-			"conductor":          {Enabled: true},
-			"block":              {Enabled: true},
-			"weekly":             {Enabled: true},
-			"context":            {Enabled: true},
-			"conductor_workflow": {Enabled: true},
+			"directory":          {Enabled: boolPtr(true)},
+			"git":                {Enabled: boolPtr(true)},
+			"model":              {Enabled: boolPtr(true)},
+			"conductor":          {Enabled: boolPtr(true)},
+			"block":              {Enabled: boolPtr(true)},
+			"weekly":             {Enabled: boolPtr(true)},
+			"context":            {Enabled: boolPtr(true)},
+			"conductor_workflow": {Enabled: boolPtr(true)},
+			"session":            {Enabled: boolPtr(true)},
+			"turns":              {Enabled: boolPtr(true)},
+			"tools":              {Enabled: boolPtr(true)},
+			"last_tool":          {Enabled: boolPtr(true)},
+			"files_edited":       {Enabled: boolPtr(true)},
+			"tokens":             {Enabled: boolPtr(true)},
+			"remote":             {Enabled: boolPtr(true)},
+			"diff":               {Enabled: boolPtr(true)},
 		},
 		SegmentOrder:   []string{"directory", "git", "model", "block", "weekly", "context", "conductor", "conductor_workflow"},
 		APITimeout:     Duration{5 * time.Second},
 		CacheTTL:       Duration{60 * time.Second},
+		GitTimeout:     Duration{time.Second},
 		TrendThreshold: 2.0,
+		ProjectKeys:    append([]string(nil), DefaultProjectKeys...),
+		Thresholds: map[string]ThresholdConfig{
+			"block":  {Warning: 70, Critical: 90},
+			"weekly": {Warning: 70, Critical: 90},
+			"opus":   {Warning: 70, Critical: 90},
+			"sonnet": {Warning: 70, Critical: 90},
+			// Percent of the session segment's budget option.
+			"session": {Warning: 70, Critical: 90},
+			// Context percentages are whole numbers; 81 means "above 80%".
+			"context": {Warning: 50, Critical: 81},
+			// Lines added plus deleted, not a percentage.
+			"diff": {Warning: 200, Critical: 500},
+		},
 	}
 }
 
-// LoadFromFile reads and parses a JSON config file. Returns a zero-value Config
-// if the file does not exist. Returns an error for malformed JSON.
+// LoadFromFile reads and parses a config file. The format is chosen by
+// extension: .yaml/.yml and .toml are converted to JSON first, anything else
+// is parsed as JSON. Returns a zero-value Config if the file does not exist.
+// Returns an error for malformed input.
 func LoadFromFile(path string) (Config, error) {
 	var cfg Config
 
@@ -44,6 +74,11 @@ func LoadFromFile(path string) (Config, error) {
 		return cfg, err
 	}
 
+	data, err = toJSON(path, data)
+	if err != nil {
+		return cfg, err
+	}
+
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return cfg, err
 	}
@@ -68,16 +103,30 @@ func MergeConfig(base, override Config) Config {
 	}
 
 	if override.Segments != nil {
-		if merged.Segments == nil {
-			merged.Segments = make(map[string]SegmentConfig)
+		merged.Segments = make(map[string]SegmentConfig, len(base.Segments)+len(override.Segments))
+		for k, v := range base.Segments {
+			merged.Segments[k] = v
 		}
 		for k, v := range override.Segments {
-			merged.Segments[k] = v
+			merged.Segments[k] = mergeSegment(merged.Segments[k], v)
 		}
 	}
 
+	// A full order replaces the inherited one; order edits apply on top of
+	// it, or wait for a lower layer when there is nothing to edit yet (e.g.
+	// a file merged onto the one it extends).
 	if len(override.SegmentOrder) > 0 {
 		merged.SegmentOrder = override.SegmentOrder
+		merged.orderOps = nil
+	}
+	if len(override.orderOps) > 0 {
+		if len(merged.SegmentOrder) > 0 {
+			for _, ops := range override.orderOps {
+				merged.SegmentOrder = ops.Apply(merged.SegmentOrder)
+			}
+		} else {
+			merged.orderOps = append(append([]OrderOps(nil), base.orderOps...), override.orderOps...)
+		}
 	}
 
 	if override.APITimeout.Duration != 0 {
@@ -88,31 +137,211 @@ func MergeConfig(base, override Config) Config {
 		merged.CacheTTL = override.CacheTTL
 	}
 
+	if override.GitTimeout.Duration != 0 {
+		merged.GitTimeout = override.GitTimeout
+	}
+
 	if override.TrendThreshold != 0 {
 		merged.TrendThreshold = override.TrendThreshold
 	}
 
+	if override.Thresholds != nil {
+		merged.Thresholds = mergeThresholds(base.Thresholds, override.Thresholds)
+	}
+
+	if len(override.ProjectKeys) > 0 {
+		merged.ProjectKeys = override.ProjectKeys
+	}
+
+	// Profiles accumulate across layers so user and project profiles are
+	// all evaluated; later ones win when several match.
+	if len(override.Profiles) > 0 {
+		merged.Profiles = append(append([]Profile(nil), base.Profiles...), override.Profiles...)
+	}
+
+	return merged
+}
+
+// mergeThresholds merges threshold maps key-by-key. Within a key, non-zero
+// warning/critical values replace the base individually, and a non-empty
+// Levels list replaces the base scale entirely.
+func mergeThresholds(base, override map[string]ThresholdConfig) map[string]ThresholdConfig {
+	merged := make(map[string]ThresholdConfig, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, o := range override {
+		t := merged[k]
+		if o.Warning != 0 {
+			t.Warning = o.Warning
+		}
+		if o.Critical != 0 {
+			t.Critical = o.Critical
+		}
+		if len(o.Levels) > 0 {
+			t.Levels = o.Levels
+		}
+		merged[k] = t
+	}
 	return merged
 }
 
+// mergeSegment overlays the fields set in override onto base. Options merge
+// key by key.
+func mergeSegment(base, override SegmentConfig) SegmentConfig {
+	if override.Type != "" {
+		base.Type = override.Type
+	}
+	if override.Enabled != nil {
+		base.Enabled = override.Enabled
+	}
+	if override.Options != nil {
+		opts := make(map[string]any, len(base.Options)+len(override.Options))
+		for k, v := range base.Options {
+			opts[k] = v
+		}
+		for k, v := range override.Options {
+			opts[k] = v
+		}
+		base.Options = opts
+	}
+	return base
+}
+
+// Sources lists the layers merged on top of the defaults, in increasing
+// precedence: user file, project files (outermost first), then each override
+// in order (typically environment variables followed by command-line flags).
+// When Match is set, profiles declared in the files are applied after the
+// file layers and before the overrides.
+//
+// Unless ProjectTrusted is set, project files may only set the keys listed in
+// the user config's projectKeys, and only the enabled switch of segments;
+// anything else is dropped with a diagnostic.
+type Sources struct {
+	UserPath       string
+	ProjectPaths   []string
+	ProjectTrusted bool
+	Match          *MatchContext
+	Overrides      []Config
+}
+
 // Load resolves configuration by loading project-level config, then user-level
 // config, and merging both on top of defaults. Pass empty strings to skip a level.
+// The project file is trusted; use LoadSources to restrict it.
 func Load(projectPath, userPath string) Config {
+	src := Sources{UserPath: userPath, ProjectTrusted: true}
+	if projectPath != "" {
+		src.ProjectPaths = []string{projectPath}
+	}
+	cfg, _ := LoadSources(src)
+	return cfg
+}
+
+// LoadSources merges defaults, the user and project files, and the overrides
+// in src. Empty paths are skipped; unreadable or malformed files are ignored
+// and bad values within a file are dropped, each reported as a Diagnostic.
+// Each file's extends chain is resolved before it is merged.
+func LoadSources(src Sources) (Config, []Diagnostic) {
+	var diags []Diagnostic
 	cfg := DefaultConfig()
 
-	if userPath != "" {
-		userCfg, err := LoadFromFile(userPath)
-		if err == nil {
+	if src.UserPath != "" {
+		userCfg, _, err := loadLayer(src.UserPath, nil, &diags)
+		if err != nil {
+			diags = append(diags, Diagnostic{Source: src.UserPath, Message: "ignored: " + err.Error()})
+		} else {
 			cfg = MergeConfig(cfg, userCfg)
 		}
 	}
 
-	if projectPath != "" {
-		projectCfg, err := LoadFromFile(projectPath)
-		if err == nil {
-			cfg = MergeConfig(cfg, projectCfg)
+	allowed := func(key string) bool { return !slices.Contains(userOnlyKeys, key) }
+	reason := "only allowed in the user config"
+	if !src.ProjectTrusted {
+		projectKeys := cfg.ProjectKeys
+		allowed = func(key string) bool { return slices.Contains(projectKeys, key) && !slices.Contains(userOnlyKeys, key) }
+		reason = `not applied: project config is not trusted (run "conductor-powerline allow")`
+	}
+	for _, path := range src.ProjectPaths {
+		if path == "" {
+			continue
+		}
+		fileCfg, _, err := loadLayer(path, nil, &diags)
+		if err != nil {
+			diags = append(diags, Diagnostic{Source: path, Message: "ignored: " + err.Error()})
+			continue
 		}
+		fileCfg, dropped := restrictKeys(fileCfg, allowed)
+		if !src.ProjectTrusted {
+			var segs []string
+			fileCfg, segs = restrictSegments(fileCfg)
+			dropped = append(dropped, segs...)
+		}
+		for _, key := range dropped {
+			diags = append(diags, Diagnostic{Source: path, Key: key, Message: reason})
+		}
+		cfg = MergeConfig(cfg, fileCfg)
 	}
 
-	return cfg
+	if src.Match != nil {
+		var applied []string
+		cfg, applied = ApplyProfiles(cfg, *src.Match)
+		debug.Logf("config", "profiles applied: %v", applied)
+	}
+
+	for _, o := range src.Overrides {
+		checkValues(o, "", reporter{source: "overrides", diags: &diags})
+		cfg = MergeConfig(cfg, o)
+	}
+
+	return cfg, dropDefinedSegments(diags, cfg)
+}
+
+// loadLayer loads path and, when it sets extends, merges it on top of the
+// file it extends. chain holds the absolute paths currently being resolved
+// and is used to reject cycles. files lists the absolute paths read, path
+// first and then each file it extends in turn, including those read before
+// an error.
+func loadLayer(path string, chain []string, diags *[]Diagnostic) (cfg Config, files []string, err error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Config{}, nil, err
+	}
+	for _, p := range chain {
+		if p == abs {
+			return Config{}, nil, fmt.Errorf("extends cycle: %s", strings.Join(append(chain, abs), " -> "))
+		}
+	}
+	chain = append(chain, abs)
+
+	cfg, err = decodeFile(abs, diags)
+	files = []string{abs}
+	if err != nil || cfg.Extends == "" {
+		return cfg, files, err
+	}
+
+	target := resolveExtends(filepath.Dir(abs), cfg.Extends)
+	if _, err := os.Stat(target); err != nil {
+		return Config{}, files, fmt.Errorf("%s: extends %q: %w", abs, cfg.Extends, err)
+	}
+	base, baseFiles, err := loadLayer(target, chain, diags)
+	files = append(files, baseFiles...)
+	if err != nil {
+		return Config{}, files, err
+	}
+	cfg.Extends = ""
+	return MergeConfig(base, cfg), files, nil
+}
+
+// resolveExtends resolves an extends value relative to dir, expanding a
+// leading ~/ to the home directory.
+func resolveExtends(dir, ref string) string {
+	if strings.HasPrefix(ref, "~/") || strings.HasPrefix(ref, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, ref[2:])
+		}
+	}
+	if filepath.IsAbs(ref) {
+		return ref
+	}
+	return filepath.Join(dir, ref)
 }
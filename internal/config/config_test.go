@@ -34,7 +34,7 @@ func TestDefaultConfig(t *testing.T) {
 			t.Errorf("expected segment %q in defaults", name)
 			continue
 		}
-		if !seg.Enabled {
+		if !seg.IsEnabled() {
 			t.Errorf("expected segment %q enabled by default", name)
 		}
 	}
@@ -45,6 +45,9 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.CacheTTL.Duration != 60*time.Second {
 		t.Errorf("expected default CacheTTL 60s, got %v", cfg.CacheTTL.Duration)
 	}
+	if cfg.GitTimeout.Duration != time.Second {
+		t.Errorf("expected default GitTimeout 1s, got %v", cfg.GitTimeout.Duration)
+	}
 	if cfg.TrendThreshold != 2.0 {
 		t.Errorf("expected default TrendThreshold 2.0, got %f", cfg.TrendThreshold)
 	}
@@ -77,7 +80,7 @@ func TestLoadFromFile(t *testing.T) {
 	if !ok {
 		t.Fatal("expected git segment in config")
 	}
-	if gitSeg.Enabled {
+	if gitSeg.IsEnabled() {
 		t.Error("expected git segment disabled")
 	}
 }
@@ -114,7 +117,7 @@ func TestMergeConfig(t *testing.T) {
 			CompactWidth: 60,
 		},
 		Segments: map[string]SegmentConfig{
-			"git": {Enabled: false},
+			"git": {Enabled: boolPtr(false)},
 		},
 		SegmentOrder: []string{"model", "directory"},
 	}
@@ -135,7 +138,7 @@ func TestMergeConfig(t *testing.T) {
 	if !ok {
 		t.Fatal("expected git segment in merged config")
 	}
-	if gitSeg.Enabled {
+	if gitSeg.IsEnabled() {
 		t.Error("expected git segment disabled after merge")
 	}
 	// directory segment should still exist from base
@@ -143,7 +146,7 @@ func TestMergeConfig(t *testing.T) {
 	if !ok {
 		t.Fatal("expected directory segment preserved from base")
 	}
-	if !dirSeg.Enabled {
+	if !dirSeg.IsEnabled() {
 		t.Error("expected directory segment still enabled")
 	}
 	// Segment order should be overridden
@@ -182,6 +185,7 @@ func TestMergeConfigNewFields(t *testing.T) {
 	override := Config{
 		APITimeout:     Duration{10 * time.Second},
 		CacheTTL:       Duration{60 * time.Second},
+		GitTimeout:     Duration{3 * time.Second},
 		TrendThreshold: 5.0,
 	}
 
@@ -193,6 +197,9 @@ func TestMergeConfigNewFields(t *testing.T) {
 	if merged.CacheTTL.Duration != 60*time.Second {
 		t.Errorf("expected CacheTTL 60s, got %v", merged.CacheTTL.Duration)
 	}
+	if merged.GitTimeout.Duration != 3*time.Second {
+		t.Errorf("expected GitTimeout 3s, got %v", merged.GitTimeout.Duration)
+	}
 	if merged.TrendThreshold != 5.0 {
 		t.Errorf("expected TrendThreshold 5.0, got %f", merged.TrendThreshold)
 	}
@@ -241,12 +248,12 @@ func TestLoad(t *testing.T) {
 		t.Errorf("expected theme 'tokyo-night', got %q", cfg.Theme)
 	}
 	modelSeg := cfg.Segments["model"]
-	if modelSeg.Enabled {
+	if modelSeg.IsEnabled() {
 		t.Error("expected model segment disabled")
 	}
 	// directory should still be enabled from defaults
 	dirSeg := cfg.Segments["directory"]
-	if !dirSeg.Enabled {
+	if !dirSeg.IsEnabled() {
 		t.Error("expected directory segment enabled from defaults")
 	}
 }
@@ -285,3 +292,212 @@ func TestLoadNoFiles(t *testing.T) {
 		t.Errorf("expected 8 default segments, got %d", len(cfg.SegmentOrder))
 	}
 }
+
+func TestDefaultThresholds(t *testing.T) {
+	cfg := DefaultConfig()
+
+	block := cfg.Thresholds["block"]
+	if block.Warning != 70 || block.Critical != 90 {
+		t.Errorf("expected block thresholds 70/90, got %v/%v", block.Warning, block.Critical)
+	}
+	ctx := cfg.Thresholds["context"]
+	if ctx.Warning != 50 || ctx.Critical != 81 {
+		t.Errorf("expected context thresholds 50/81, got %v/%v", ctx.Warning, ctx.Critical)
+	}
+}
+
+func TestMergeConfigThresholds(t *testing.T) {
+	base := DefaultConfig()
+	override := Config{
+		Thresholds: map[string]ThresholdConfig{
+			"block":  {Warning: 50},
+			"weekly": {Levels: []ThresholdLevel{{At: 85, Color: "critical"}}},
+		},
+	}
+
+	merged := MergeConfig(base, override)
+
+	block := merged.Thresholds["block"]
+	if block.Warning != 50 {
+		t.Errorf("expected block warning 50, got %v", block.Warning)
+	}
+	if block.Critical != 90 {
+		t.Errorf("expected block critical preserved at 90, got %v", block.Critical)
+	}
+	weekly := merged.Thresholds["weekly"].ResolvedLevels()
+	if len(weekly) != 1 || weekly[0].At != 85 {
+		t.Errorf("expected weekly levels replaced by [85], got %v", weekly)
+	}
+	if _, ok := merged.Thresholds["context"]; !ok {
+		t.Error("expected context thresholds preserved from base")
+	}
+	if base.Thresholds["block"].Warning != 70 {
+		t.Error("merge must not mutate base thresholds")
+	}
+}
+
+func TestThresholdResolvedLevels(t *testing.T) {
+	levels := ThresholdConfig{Warning: 60, Critical: 85}.ResolvedLevels()
+	if len(levels) != 2 || levels[0].Color != "warning" || levels[1].At != 85 {
+		t.Errorf("unexpected levels: %v", levels)
+	}
+	if got := (ThresholdConfig{Critical: 95}).ResolvedLevels(); len(got) != 1 {
+		t.Errorf("expected only critical level, got %v", got)
+	}
+}
+
+func TestLoadFromFileThresholds(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, ".conductor-powerline.json")
+
+	content := `{"thresholds": {"weekly": {"warning": 85, "critical": 95}}}`
+	if err := os.WriteFile(cfgPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Load(cfgPath, "")
+	weekly := cfg.Thresholds["weekly"]
+	if weekly.Warning != 85 || weekly.Critical != 95 {
+		t.Errorf("expected weekly thresholds 85/95, got %v/%v", weekly.Warning, weekly.Critical)
+	}
+	if cfg.Thresholds["block"].Warning != 70 {
+		t.Errorf("expected block default preserved, got %v", cfg.Thresholds["block"].Warning)
+	}
+}
+
+func TestLoadFromFileYAML(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, ".conductor-powerline.yaml")
+
+	content := "theme: gruvbox\napiTimeout: 3s\nsegments:\n  git:\n    enabled: false\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Theme != "gruvbox" {
+		t.Errorf("expected theme 'gruvbox', got %q", cfg.Theme)
+	}
+	if cfg.APITimeout.Duration != 3*time.Second {
+		t.Errorf("expected APITimeout 3s, got %v", cfg.APITimeout.Duration)
+	}
+	if seg, ok := cfg.Segments["git"]; !ok || seg.IsEnabled() {
+		t.Error("expected git segment disabled")
+	}
+}
+
+func TestLoadFromFileTOML(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, ".conductor-powerline.toml")
+
+	content := "theme = \"rose-pine\"\ncacheTTL = \"2m\"\n[display]\ncompactWidth = 70\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Theme != "rose-pine" {
+		t.Errorf("expected theme 'rose-pine', got %q", cfg.Theme)
+	}
+	if cfg.CacheTTL.Duration != 2*time.Minute {
+		t.Errorf("expected CacheTTL 2m, got %v", cfg.CacheTTL.Duration)
+	}
+	if cfg.Display.CompactWidth != 70 {
+		t.Errorf("expected CompactWidth 70, got %d", cfg.Display.CompactWidth)
+	}
+}
+
+func TestLoadFromFileMalformedYAML(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, ".conductor-powerline.yml")
+	if err := os.WriteFile(cfgPath, []byte("theme: [unterminated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFromFile(cfgPath); err == nil {
+		t.Error("expected error for malformed YAML")
+	}
+}
+
+func TestFindFilePrefersJSON(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{".conductor-powerline.toml", ".conductor-powerline.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := FindFile(dir, ".conductor-powerline")
+	if filepath.Base(got) != ".conductor-powerline.json" {
+		t.Errorf("expected JSON file preferred, got %q", got)
+	}
+}
+
+func TestFindFileFallsBackToOtherFormats(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".conductor-powerline.yaml"), []byte("theme: nord"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := FindFile(dir, ".conductor-powerline")
+	if filepath.Base(got) != ".conductor-powerline.yaml" {
+		t.Errorf("expected YAML file, got %q", got)
+	}
+	if FindFile(t.TempDir(), ".conductor-powerline") != "" {
+		t.Error("expected empty path when no config exists")
+	}
+}
+
+func TestMergeConfigDeepMergesSegments(t *testing.T) {
+	base := DefaultConfig()
+	base.Segments["git"] = SegmentConfig{Enabled: boolPtr(false)}
+
+	// An entry that sets nothing must not reset what lower layers chose.
+	merged := MergeConfig(base, Config{Segments: map[string]SegmentConfig{"git": {}}})
+	if merged.Segments["git"].IsEnabled() {
+		t.Error("expected git to stay disabled when override leaves enabled unset")
+	}
+
+	merged = MergeConfig(base, Config{Segments: map[string]SegmentConfig{"git": {Enabled: boolPtr(true)}}})
+	if !merged.Segments["git"].IsEnabled() {
+		t.Error("expected explicit enabled to win")
+	}
+	if base.Segments["git"].IsEnabled() {
+		t.Error("MergeConfig must not modify the base segments map")
+	}
+}
+
+func TestSegmentConfigIsEnabledDefault(t *testing.T) {
+	if !(SegmentConfig{}).IsEnabled() {
+		t.Error("expected IsEnabled() true when Enabled is nil")
+	}
+}
+
+func TestMergeConfigMergesSegmentOptions(t *testing.T) {
+	base := Config{Segments: map[string]SegmentConfig{
+		"git": {Options: map[string]any{"dirtyMarker": "±", "other": 1}},
+	}}
+	override := Config{Segments: map[string]SegmentConfig{
+		"git": {Enabled: boolPtr(true), Options: map[string]any{"dirtyMarker": "!"}},
+	}}
+
+	got := MergeConfig(base, override).Segments["git"]
+	if got.Options["dirtyMarker"] != "!" || got.Options["other"] != 1 {
+		t.Errorf("expected options merged per key, got %v", got.Options)
+	}
+	if base.Segments["git"].Options["dirtyMarker"] != "±" {
+		t.Error("MergeConfig must not modify the base options")
+	}
+
+	// Toggling enabled alone keeps options from lower layers.
+	got = MergeConfig(base, Config{Segments: map[string]SegmentConfig{"git": {Enabled: boolPtr(false)}}}).Segments["git"]
+	if got.Options["dirtyMarker"] != "±" {
+		t.Errorf("expected options kept, got %v", got.Options)
+	}
+}
@@ -0,0 +1,287 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+)
+
+// Diagnostic is a problem found while loading configuration. Loading never
+// fails outright; the offending value, or at worst the offending file, is
+// skipped and a Diagnostic records why.
+type Diagnostic struct {
+	Source  string // config file path, or the name of an override layer
+	Key     string // dotted key path, e.g. "segments.gti"; empty for whole-file errors
+	Message string
+
+	// segment is set when the problem is an unknown segment name, which may
+	// still be a custom segment defined by another layer.
+	segment string
+}
+
+func (d Diagnostic) String() string {
+	if d.Key == "" {
+		return d.Source + ": " + d.Message
+	}
+	return d.Source + ": " + d.Key + ": " + d.Message
+}
+
+// decodeFile reads a config file like LoadFromFile but tolerates bad values:
+// unknown keys, invalid durations and mistyped fields are reported as
+// diagnostics and skipped while the rest of the file still applies. Only
+// unreadable or unparseable files return an error.
+func decodeFile(path string, diags *[]Diagnostic) (Config, error) {
+	var cfg Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	data, err = toJSON(path, data)
+	if err != nil {
+		return cfg, err
+	}
+
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return cfg, err
+	}
+	r := reporter{source: path, diags: diags}
+	checkRaw(raw, reflect.TypeOf(cfg), "", r.report)
+
+	data, err = json.Marshal(raw)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		// Type mismatches leave the field unset but decoding continues, so
+		// everything else in the file is still usable.
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return cfg, err
+		}
+		r.report(typeErr.Field, fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value))
+	}
+	checkValues(cfg, "", r)
+	return cfg, nil
+}
+
+// reporter appends diagnostics for one source.
+type reporter struct {
+	source string
+	diags  *[]Diagnostic
+}
+
+func (r reporter) report(key, msg string) {
+	*r.diags = append(*r.diags, Diagnostic{Source: r.source, Key: key, Message: msg})
+}
+
+// unknownSegment reports a reference to a segment name that is neither
+// built in nor defined in this layer. dropDefinedSegments withdraws it if
+// another layer defines the name as a custom segment.
+func (r reporter) unknownSegment(key, msg, name string) {
+	*r.diags = append(*r.diags, Diagnostic{Source: r.source, Key: key, Message: msg, segment: name})
+}
+
+// dropDefinedSegments removes unknown-segment diagnostics for names the
+// merged config defines as custom segments.
+func dropDefinedSegments(diags []Diagnostic, cfg Config) []Diagnostic {
+	kept := diags[:0]
+	for _, d := range diags {
+		if d.segment != "" && cfg.Segments[d.segment].Type != "" {
+			continue
+		}
+		kept = append(kept, d)
+	}
+	return kept
+}
+
+var (
+	durationType = reflect.TypeOf(Duration{})
+	configType   = reflect.TypeOf(Config{})
+	orderOpsType = reflect.TypeOf(OrderOps{})
+)
+
+// checkRaw walks a decoded JSON value against the Go type it will be decoded
+// into, reporting unknown keys and invalid durations. It returns true when v
+// would abort decoding, so the caller can drop it from its parent.
+func checkRaw(v any, t reflect.Type, key string, report func(key, msg string)) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType {
+		s, _ := v.(string)
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			report(key, fmt.Sprintf("invalid duration %s (want e.g. \"5s\")", jsonString(v)))
+			return true
+		}
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return false // reported as a type mismatch when decoding
+		}
+		for _, k := range sortedKeys(m) {
+			f, ok := jsonField(t, k)
+			if !ok {
+				report(joinKey(key, k), "unknown key")
+				delete(m, k)
+				continue
+			}
+			ft := f.Type
+			if _, isOps := m[k].(map[string]any); isOps && t == configType && f.Name == "SegmentOrder" {
+				ft = orderOpsType
+			}
+			if checkRaw(m[k], ft, joinKey(key, k), report) {
+				delete(m, k)
+			}
+		}
+	case reflect.Map:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return false
+		}
+		for _, k := range sortedKeys(m) {
+			if checkRaw(m[k], t.Elem(), joinKey(key, k), report) {
+				delete(m, k)
+			}
+		}
+	case reflect.Slice:
+		items, ok := v.([]any)
+		if !ok {
+			return false
+		}
+		for i, item := range items {
+			checkRaw(item, t.Elem(), fmt.Sprintf("%s[%d]", key, i), report)
+		}
+	}
+	return false
+}
+
+// checkValues reports names the config refers to that do not exist: themes,
+// segments, segment types and threshold scales. Profiles are checked
+// recursively.
+func checkValues(cfg Config, prefix string, r reporter) {
+	if cfg.Theme != "" && !slices.Contains(themes.Names(), cfg.Theme) {
+		r.report(prefix+"theme", fmt.Sprintf("unknown theme %q (available: %s)", cfg.Theme, strings.Join(themes.Names(), ", ")))
+	}
+	builtin := SegmentNames()
+	known := func(name string) bool {
+		return slices.Contains(builtin, name) || cfg.Segments[name].Type != ""
+	}
+	for _, name := range sortedKeys(cfg.Segments) {
+		typ := cfg.Segments[name].Type
+		switch {
+		case typ != "" && slices.Contains(builtin, name):
+			r.report(prefix+"segments."+name+".type", "type can only be set on custom segments, not built-in ones")
+		case typ != "" && !slices.Contains(SegmentTypes, typ):
+			r.report(prefix+"segments."+name+".type", fmt.Sprintf("unknown segment type %q (available: %s)", typ, strings.Join(SegmentTypes, ", ")))
+		case !known(name):
+			r.unknownSegment(prefix+"segments."+name, "unknown segment", name)
+		}
+	}
+	for i, name := range cfg.SegmentOrder {
+		if !known(name) {
+			r.unknownSegment(fmt.Sprintf("%ssegmentOrder[%d]", prefix, i), fmt.Sprintf("unknown segment %q", name), name)
+		}
+	}
+	for _, ops := range cfg.orderOps {
+		for _, name := range ops.names() {
+			if !known(name) {
+				r.unknownSegment(prefix+"segmentOrder", fmt.Sprintf("unknown segment %q", name), name)
+			}
+		}
+	}
+	for _, name := range sortedKeys(cfg.Thresholds) {
+		if !slices.Contains(thresholdNames, name) {
+			r.report(prefix+"thresholds."+name, "unknown threshold")
+		}
+	}
+	for i, key := range cfg.ProjectKeys {
+		if !slices.Contains(configKeys(), key) {
+			r.report(fmt.Sprintf("%sprojectKeys[%d]", prefix, i), fmt.Sprintf("unknown key %q", key))
+		}
+	}
+	for i, p := range cfg.Profiles {
+		checkValues(p.Config, fmt.Sprintf("%sprofiles[%d].config.", prefix, i), r)
+	}
+}
+
+// configKeys returns the top-level JSON keys of Config.
+func configKeys() []string {
+	var keys []string
+	for i := 0; i < configType.NumField(); i++ {
+		f := configType.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		keys = append(keys, strings.Split(f.Tag.Get("json"), ",")[0])
+	}
+	return keys
+}
+
+// jsonField finds the struct field decoded from key. Like encoding/json,
+// an exact tag match wins and a case-insensitive one is accepted.
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	var fold reflect.StructField
+	found := false
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if name == key {
+			return f, true
+		}
+		if !found && strings.EqualFold(name, key) {
+			fold, found = f, true
+		}
+	}
+	return fold, found
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func jsonString(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
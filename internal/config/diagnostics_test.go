@@ -0,0 +1,151 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func diagStrings(diags []Diagnostic) []string {
+	out := make([]string, len(diags))
+	for i, d := range diags {
+		out[i] = d.Key + ": " + d.Message
+	}
+	return out
+}
+
+func TestLoadSourcesReportsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	writeFile(t, path, `{"$schema": "x", "theme": "nord", "segmnets": {}, "display": {"nerdFont": false}}`)
+
+	cfg, diags := LoadSources(Sources{UserPath: path})
+	got := diagStrings(diags)
+	want := []string{"display.nerdFont: unknown key", "segmnets: unknown key"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("diagnostics = %v, want %v", got, want)
+	}
+	if cfg.Theme != "nord" {
+		t.Errorf("expected valid keys to still apply, got theme %q", cfg.Theme)
+	}
+	if diags[0].Source != path {
+		t.Errorf("expected source %q, got %q", path, diags[0].Source)
+	}
+}
+
+func TestLoadSourcesDropsInvalidDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.yaml")
+	writeFile(t, path, "theme: nord\napiTimeout: 5\ncacheTTL: 2m\n")
+
+	cfg, diags := LoadSources(Sources{UserPath: path})
+	if len(diags) != 1 || diags[0].Key != "apiTimeout" || !strings.Contains(diags[0].Message, "invalid duration 5") {
+		t.Fatalf("expected one apiTimeout diagnostic, got %v", diags)
+	}
+	if cfg.APITimeout.Duration != 5*time.Second {
+		t.Errorf("expected default timeout kept, got %v", cfg.APITimeout.Duration)
+	}
+	if cfg.CacheTTL.Duration != 2*time.Minute || cfg.Theme != "nord" {
+		t.Errorf("expected rest of file applied, got ttl=%v theme=%q", cfg.CacheTTL.Duration, cfg.Theme)
+	}
+}
+
+func TestLoadSourcesReportsUnknownNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	writeFile(t, path, `{
+		"theme": "drak",
+		"segments": {"gti": {"enabled": true}},
+		"segmentOrder": ["model", "modle"],
+		"thresholds": {"blok": {"warning": 1}},
+		"profiles": [{"config": {"theme": "nope"}}]
+	}`)
+
+	_, diags := LoadSources(Sources{UserPath: path})
+	got := strings.Join(diagStrings(diags), "\n")
+	for _, want := range []string{
+		`theme: unknown theme "drak"`,
+		"segments.gti: unknown segment",
+		`segmentOrder[1]: unknown segment "modle"`,
+		"thresholds.blok: unknown threshold",
+		`profiles[0].config.theme: unknown theme "nope"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in diagnostics:\n%s", want, got)
+		}
+	}
+}
+
+func TestLoadSourcesReportsTypeMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	writeFile(t, path, `{"theme": "nord", "display": {"compactWidth": "wide"}}`)
+
+	cfg, diags := LoadSources(Sources{UserPath: path})
+	if len(diags) != 1 || diags[0].Key != "display.compactWidth" {
+		t.Fatalf("expected one compactWidth diagnostic, got %v", diags)
+	}
+	if cfg.Theme != "nord" {
+		t.Errorf("expected rest of file applied, got theme %q", cfg.Theme)
+	}
+}
+
+func TestLoadSourcesReportsMalformedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	writeFile(t, path, `{invalid`)
+
+	cfg, diags := LoadSources(Sources{UserPath: path})
+	if len(diags) != 1 || !strings.HasPrefix(diags[0].String(), path+": ignored: ") {
+		t.Fatalf("expected file ignored diagnostic, got %v", diags)
+	}
+	if cfg.Theme != DefaultConfig().Theme {
+		t.Errorf("expected defaults, got theme %q", cfg.Theme)
+	}
+}
+
+func TestLoadSourcesChecksOverrides(t *testing.T) {
+	_, diags := LoadSources(Sources{Overrides: []Config{{SegmentOrder: []string{"modle"}}}})
+	if len(diags) != 1 || diags[0].String() != `overrides: segmentOrder[0]: unknown segment "modle"` {
+		t.Errorf("unexpected diagnostics %v", diags)
+	}
+}
+
+func TestLoadSourcesCleanConfigHasNoDiagnostics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.toml")
+	writeFile(t, path, "theme = \"nord\"\napiTimeout = \"3s\"\n[segments.model]\nenabled = false\n")
+
+	if _, diags := LoadSources(Sources{UserPath: path}); len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+}
+
+func TestLoadSourcesCustomSegments(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.json")
+	project := filepath.Join(dir, "project", ".conductor-powerline.json")
+	writeFile(t, user, `{"segments": {"style": {"type": "hook_field", "options": {"pointer": "/output_style/name"}}}}`)
+	writeFile(t, project, `{
+		"segments": {"style": {"enabled": true}, "model": {"type": "hook_field"}, "odd": {"type": "nope"}},
+		"segmentOrder": {"append": ["style", "stlye"]}
+	}`)
+
+	cfg, diags := LoadSources(Sources{UserPath: user, ProjectPaths: []string{project}})
+	got := strings.Join(diagStrings(diags), "\n")
+	for _, want := range []string{
+		"segments.model.type: type can only be set on custom segments",
+		`segments.odd.type: unknown segment type "nope"`,
+		`segmentOrder: unknown segment "stlye"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in diagnostics:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, `"style"`) || strings.Contains(got, "segments.style") {
+		t.Errorf("expected segment defined in the user config to be known to the project:\n%s", got)
+	}
+
+	style := cfg.Segments["style"]
+	if style.Type != "hook_field" || style.Options["pointer"] != "/output_style/name" || !style.IsEnabled() {
+		t.Errorf("expected custom segment merged across layers, got %+v", style)
+	}
+	if cfg.SegmentOrder[len(cfg.SegmentOrder)-2] != "style" {
+		t.Errorf("expected custom segment appended to order, got %v", cfg.SegmentOrder)
+	}
+}
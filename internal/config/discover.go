@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ProjectFileBase is the base name (without extension) of project config
+// files, e.g. .conductor-powerline.json.
+const ProjectFileBase = ".conductor-powerline"
+
+// DiscoverProjectFiles walks from dir up to the enclosing git root (the first
+// directory containing a .git entry) or, outside a repository, the filesystem
+// root. It returns the project config files found, farthest first, so that
+// merging them in order lets the nearest file win.
+func DiscoverProjectFiles(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+
+	var found []string
+	for {
+		if path := FindFile(dir, ProjectFileBase); path != "" {
+			found = append(found, path)
+		}
+		if isGitRoot(dir) {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	// Reverse so the outermost file comes first.
+	for i, j := 0, len(found)-1; i < j; i, j = i+1, j-1 {
+		found[i], found[j] = found[j], found[i]
+	}
+	return found
+}
+
+// isGitRoot reports whether dir contains a .git directory or file (the latter
+// marks linked worktrees and submodules).
+func isGitRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscoverProjectFilesStopsAtGitRoot(t *testing.T) {
+	outer := t.TempDir()
+	repo := filepath.Join(outer, "repo")
+	pkg := filepath.Join(repo, "packages", "api")
+
+	writeFile(t, filepath.Join(outer, ".conductor-powerline.json"), `{}`)
+	writeFile(t, filepath.Join(repo, ".conductor-powerline.json"), `{}`)
+	writeFile(t, filepath.Join(repo, "packages", ".conductor-powerline.yaml"), `theme: nord`)
+	writeFile(t, filepath.Join(pkg, ".conductor-powerline.toml"), `theme = "gruvbox"`)
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got := DiscoverProjectFiles(pkg)
+	want := []string{
+		filepath.Join(repo, ".conductor-powerline.json"),
+		filepath.Join(repo, "packages", ".conductor-powerline.yaml"),
+		filepath.Join(pkg, ".conductor-powerline.toml"),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d files, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("file[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDiscoverProjectFilesGitFileMarksRoot(t *testing.T) {
+	outer := t.TempDir()
+	worktree := filepath.Join(outer, "wt")
+	writeFile(t, filepath.Join(outer, ".conductor-powerline.json"), `{}`)
+	writeFile(t, filepath.Join(worktree, ".git"), "gitdir: /elsewhere\n")
+
+	if got := DiscoverProjectFiles(worktree); len(got) != 0 {
+		t.Errorf("expected walk to stop at .git file, got %v", got)
+	}
+}
+
+func TestDiscoverProjectFilesEmptyDir(t *testing.T) {
+	if got := DiscoverProjectFiles(""); got != nil {
+		t.Errorf("expected nil for empty dir, got %v", got)
+	}
+}
+
+func TestLoadSourcesNearestProjectFileWins(t *testing.T) {
+	repo := t.TempDir()
+	pkg := filepath.Join(repo, "pkg")
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(repo, ".conductor-powerline.json"), `{"theme":"nord","segments":{"weekly":{"enabled":false}}}`)
+	writeFile(t, filepath.Join(pkg, ".conductor-powerline.json"), `{"theme":"gruvbox"}`)
+
+	cfg, _ := LoadSources(Sources{ProjectPaths: DiscoverProjectFiles(pkg)})
+
+	if cfg.Theme != "gruvbox" {
+		t.Errorf("expected nearest theme 'gruvbox', got %q", cfg.Theme)
+	}
+	if cfg.Segments["weekly"].IsEnabled() {
+		t.Error("expected repo-wide weekly setting preserved")
+	}
+}
+
+func TestLoadExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "shared", "base.json"), `{"theme":"nord","display":{"compactWidth":70}}`)
+	writeFile(t, filepath.Join(dir, "proj", ".conductor-powerline.json"), `{"extends":"../shared/base.json","theme":"light"}`)
+
+	cfg := Load(filepath.Join(dir, "proj", ".conductor-powerline.json"), "")
+
+	if cfg.Theme != "light" {
+		t.Errorf("expected extending file to win, got theme %q", cfg.Theme)
+	}
+	if cfg.Display.CompactWidth != 70 {
+		t.Errorf("expected CompactWidth 70 from extended file, got %d", cfg.Display.CompactWidth)
+	}
+	if cfg.Extends != "" {
+		t.Errorf("expected extends cleared after resolution, got %q", cfg.Extends)
+	}
+}
+
+func TestLoadExtendsChain(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "theme: nord\napiTimeout: 9s\n")
+	writeFile(t, filepath.Join(dir, "b.json"), `{"extends":"a.yaml","theme":"gruvbox"}`)
+	writeFile(t, filepath.Join(dir, "c.json"), `{"extends":"b.json"}`)
+
+	cfg, files, err := loadLayer(filepath.Join(dir, "c.json"), nil, new([]Diagnostic))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Theme != "gruvbox" {
+		t.Errorf("expected theme 'gruvbox', got %q", cfg.Theme)
+	}
+	if cfg.APITimeout.Duration.String() != "9s" {
+		t.Errorf("expected APITimeout 9s from root of chain, got %v", cfg.APITimeout.Duration)
+	}
+	want := []string{filepath.Join(dir, "c.json"), filepath.Join(dir, "b.json"), filepath.Join(dir, "a.yaml")}
+	if !slices.Equal(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+}
+
+func TestLoadExtendsCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.json"), `{"extends":"b.json","theme":"nord"}`)
+	writeFile(t, filepath.Join(dir, "b.json"), `{"extends":"a.json"}`)
+
+	if _, _, err := loadLayer(filepath.Join(dir, "a.json"), nil, new([]Diagnostic)); err == nil {
+		t.Error("expected cycle error")
+	}
+
+	// A cyclic file is skipped rather than crashing the load.
+	cfg := Load(filepath.Join(dir, "a.json"), "")
+	if cfg.Theme != "dark" {
+		t.Errorf("expected defaults when extends cycles, got theme %q", cfg.Theme)
+	}
+}
+
+func TestLoadExtendsMissingTarget(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.json"), `{"extends":"missing.json"}`)
+
+	if _, _, err := loadLayer(filepath.Join(dir, "a.json"), nil, new([]Diagnostic)); err == nil {
+		t.Error("expected error for missing extends target")
+	}
+}
+
+func TestResolveExtendsHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	got := resolveExtends("/somewhere", "~/shared.json")
+	if got != filepath.Join(home, "shared.json") {
+		t.Errorf("expected ~ expanded, got %q", got)
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Extensions lists the config file formats accepted by LoadFromFile, in the
+// order FindFile prefers them when several exist side by side.
+var Extensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// FindFile returns the first existing file named base plus one of Extensions
+// in dir, or "" when none exists. base has no extension, e.g.
+// ".conductor-powerline".
+func FindFile(dir, base string) string {
+	for _, ext := range Extensions {
+		path := filepath.Join(dir, base+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// toJSON converts config file contents to JSON based on the file extension,
+// so every format decodes through the same struct tags and Duration parsing.
+// Unknown extensions are treated as JSON.
+func toJSON(path string, data []byte) ([]byte, error) {
+	var v any
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		v, err = parseYAML(data)
+	case ".toml":
+		v, err = parseTOML(data)
+	default:
+		return data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
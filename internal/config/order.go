@@ -0,0 +1,115 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// OrderOps edits an inherited segment order instead of replacing it, so a
+// project can add one segment without re-listing the rest. Operations run
+// in field order: remove, prepend, append, then before and after.
+type OrderOps struct {
+	Remove  []string          `json:"remove,omitempty" desc:"Segments to drop from the order."`
+	Prepend []string          `json:"prepend,omitempty" desc:"Segments to move or add to the start."`
+	Append  []string          `json:"append,omitempty" desc:"Segments to move or add to the end."`
+	Before  map[string]string `json:"before,omitempty" desc:"Segment → anchor: place the segment just before the anchor."`
+	After   map[string]string `json:"after,omitempty" desc:"Segment → anchor: place the segment just after the anchor."`
+}
+
+// Apply returns order with ops applied. order is not modified. A segment
+// whose before/after anchor is not in the order is appended at the end.
+func (ops OrderOps) Apply(order []string) []string {
+	out := append([]string(nil), order...)
+	for _, name := range ops.Remove {
+		out = without(out, name)
+	}
+	for i := len(ops.Prepend) - 1; i >= 0; i-- {
+		out = append([]string{ops.Prepend[i]}, without(out, ops.Prepend[i])...)
+	}
+	for _, name := range ops.Append {
+		out = append(without(out, name), name)
+	}
+	for _, name := range sortedKeys(ops.Before) {
+		out = insertAt(without(out, name), name, ops.Before[name], 0)
+	}
+	for _, name := range sortedKeys(ops.After) {
+		out = insertAt(without(out, name), name, ops.After[name], 1)
+	}
+	return out
+}
+
+// names returns every segment name the ops refer to, for validation.
+func (ops OrderOps) names() []string {
+	names := append(append(append([]string(nil), ops.Remove...), ops.Prepend...), ops.Append...)
+	for _, name := range sortedKeys(ops.Before) {
+		names = append(names, name, ops.Before[name])
+	}
+	for _, name := range sortedKeys(ops.After) {
+		names = append(names, name, ops.After[name])
+	}
+	return names
+}
+
+// without returns order with every occurrence of name removed.
+func without(order []string, name string) []string {
+	out := order[:0:0]
+	for _, n := range order {
+		if n != name {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// insertAt inserts name next to anchor (offset 0 = before, 1 = after), or at
+// the end when anchor is missing.
+func insertAt(order []string, name, anchor string, offset int) []string {
+	for i, n := range order {
+		if n == anchor {
+			i += offset
+			return append(order[:i:i], append([]string{name}, order[i:]...)...)
+		}
+	}
+	return append(order, name)
+}
+
+// UnmarshalJSON accepts segmentOrder either as a full list or as an OrderOps
+// object. Other fields decode as usual.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type plain Config
+	aux := struct {
+		*plain
+		SegmentOrder json.RawMessage `json:"segmentOrder"`
+	}{plain: (*plain)(c)}
+
+	// Type mismatches still decode the remaining fields; report them after
+	// segmentOrder has been handled.
+	err := json.Unmarshal(data, &aux)
+	var typeErr *json.UnmarshalTypeError
+	if err != nil && !errors.As(err, &typeErr) {
+		return err
+	}
+
+	raw := bytes.TrimSpace(aux.SegmentOrder)
+	switch {
+	case len(raw) == 0 || bytes.Equal(raw, []byte("null")):
+	case raw[0] == '{':
+		var ops OrderOps
+		if err := json.Unmarshal(raw, &ops); err != nil {
+			return err
+		}
+		c.SegmentOrder = nil
+		c.orderOps = []OrderOps{ops}
+	default:
+		var order []string
+		if err := json.Unmarshal(raw, &order); err != nil {
+			if errors.As(err, &typeErr) {
+				typeErr.Field = "segmentOrder"
+			}
+			return err
+		}
+		c.SegmentOrder = order
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOrderOpsApply(t *testing.T) {
+	base := []string{"directory", "git", "model", "block"}
+	tests := []struct {
+		name string
+		ops  OrderOps
+		want string
+	}{
+		{"remove", OrderOps{Remove: []string{"git", "nope"}}, "directory,model,block"},
+		{"append new", OrderOps{Append: []string{"weekly"}}, "directory,git,model,block,weekly"},
+		{"append moves", OrderOps{Append: []string{"directory"}}, "git,model,block,directory"},
+		{"prepend keeps order", OrderOps{Prepend: []string{"model", "block"}}, "model,block,directory,git"},
+		{"before", OrderOps{Before: map[string]string{"block": "git"}}, "directory,block,git,model"},
+		{"after", OrderOps{After: map[string]string{"directory": "model"}}, "git,model,directory,block"},
+		{"missing anchor appends", OrderOps{After: map[string]string{"weekly": "nope"}}, "directory,git,model,block,weekly"},
+		{"combined", OrderOps{Remove: []string{"block"}, Append: []string{"weekly"}, Before: map[string]string{"context": "weekly"}}, "directory,git,model,context,weekly"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(tt.ops.Apply(base), ",")
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+	if strings.Join(base, ",") != "directory,git,model,block" {
+		t.Errorf("Apply modified its input: %v", base)
+	}
+}
+
+func TestConfigUnmarshalSegmentOrder(t *testing.T) {
+	var list Config
+	if err := json.Unmarshal([]byte(`{"theme": "nord", "segmentOrder": ["model", "git"]}`), &list); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(list.SegmentOrder, ",") != "model,git" || list.Theme != "nord" {
+		t.Errorf("unexpected list decode: %+v", list)
+	}
+
+	var ops Config
+	if err := json.Unmarshal([]byte(`{"segmentOrder": {"append": ["weekly"], "before": {"git": "directory"}}}`), &ops); err != nil {
+		t.Fatal(err)
+	}
+	if ops.SegmentOrder != nil || len(ops.orderOps) != 1 || ops.orderOps[0].Append[0] != "weekly" {
+		t.Errorf("expected order ops, got %+v", ops)
+	}
+
+	var bad Config
+	if err := json.Unmarshal([]byte(`{"segmentOrder": 5}`), &bad); err == nil {
+		t.Error("expected error for non-list segmentOrder")
+	}
+}
+
+func TestMergeConfigOrderOps(t *testing.T) {
+	base := DefaultConfig()
+	override := Config{orderOps: []OrderOps{{Remove: []string{"weekly", "conductor"}}}}
+
+	merged := MergeConfig(base, override)
+	for _, name := range merged.SegmentOrder {
+		if name == "weekly" || name == "conductor" {
+			t.Errorf("expected %s removed, got %v", name, merged.SegmentOrder)
+		}
+	}
+	if len(merged.SegmentOrder) != len(base.SegmentOrder)-2 {
+		t.Errorf("expected other segments kept, got %v", merged.SegmentOrder)
+	}
+}
+
+func TestLoadSourcesUserProjectOrderLayering(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.json")
+	project := filepath.Join(dir, "project.yaml")
+	writeFile(t, user, `{"segmentOrder": ["directory", "git", "model", "block"]}`)
+	writeFile(t, project, "segmentOrder:\n  append: [weekly]\n  remove: [git]\n")
+
+	cfg, diags := LoadSources(Sources{UserPath: user, ProjectPaths: []string{project}})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+	if got := strings.Join(cfg.SegmentOrder, ","); got != "directory,model,block,weekly" {
+		t.Errorf("got order %s", got)
+	}
+}
+
+func TestLoadSourcesOrderOpsThroughExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "shared.json"), `{"segmentOrder": {"prepend": ["model"]}}`)
+	writeFile(t, filepath.Join(dir, "project.json"), `{"extends": "shared.json", "segmentOrder": {"remove": ["git"]}}`)
+
+	cfg, _ := LoadSources(Sources{ProjectPaths: []string{filepath.Join(dir, "project.json")}})
+	want := MergeConfig(DefaultConfig(), Config{orderOps: []OrderOps{{Prepend: []string{"model"}}, {Remove: []string{"git"}}}})
+	if got := strings.Join(cfg.SegmentOrder, ","); got != strings.Join(want.SegmentOrder, ",") {
+		t.Errorf("expected both edits applied to the default order, got %s", got)
+	}
+	if cfg.SegmentOrder[0] != "model" {
+		t.Errorf("expected model first, got %v", cfg.SegmentOrder)
+	}
+}
+
+func TestLoadSourcesReportsUnknownOrderOps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	writeFile(t, path, `{"segmentOrder": {"append": ["gti"], "insert": ["model"]}}`)
+
+	_, diags := LoadSources(Sources{UserPath: path})
+	got := strings.Join(diagStrings(diags), "\n")
+	for _, want := range []string{"segmentOrder.insert: unknown key", `segmentOrder: unknown segment "gti"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in diagnostics:\n%s", want, got)
+		}
+	}
+}
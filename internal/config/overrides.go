@@ -0,0 +1,180 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix is prepended to the upper-cased flag name to form the environment
+// variable for each override, e.g. --api-timeout → CONDUCTOR_POWERLINE_API_TIMEOUT.
+const EnvPrefix = "CONDUCTOR_POWERLINE_"
+
+// override is a single setting exposed as both a flag and an env var.
+type override struct {
+	name   string
+	usage  string
+	isBool bool
+	apply  func(cfg *Config, value string) error
+}
+
+// overrides lists every setting that can be pinned per invocation. Each
+// apply writes into an otherwise empty Config so MergeConfig only replaces
+// what was set.
+var overrides = []override{
+	{
+		name:  "theme",
+		usage: "color theme name",
+		apply: func(cfg *Config, v string) error {
+			cfg.Theme = v
+			return nil
+		},
+	},
+	{
+		name:  "segments",
+		usage: "comma-separated segment order, e.g. model,block",
+		apply: func(cfg *Config, v string) error {
+			cfg.SegmentOrder = splitList(v)
+			return nil
+		},
+	},
+	{
+		name:  "enable",
+		usage: "comma-separated segments to enable",
+		apply: func(cfg *Config, v string) error {
+			setEnabled(cfg, splitList(v), true)
+			return nil
+		},
+	},
+	{
+		name:  "disable",
+		usage: "comma-separated segments to disable",
+		apply: func(cfg *Config, v string) error {
+			setEnabled(cfg, splitList(v), false)
+			return nil
+		},
+	},
+	{
+		name:  "api-timeout",
+		usage: "HTTP timeout for the usage API, e.g. 5s",
+		apply: func(cfg *Config, v string) error {
+			return parseDurationInto(&cfg.APITimeout, v)
+		},
+	},
+	{
+		name:  "cache-ttl",
+		usage: "cache lifetime for usage API responses, e.g. 60s",
+		apply: func(cfg *Config, v string) error {
+			return parseDurationInto(&cfg.CacheTTL, v)
+		},
+	},
+	{
+		name:  "git-timeout",
+		usage: "timeout for git commands, e.g. 500ms",
+		apply: func(cfg *Config, v string) error {
+			return parseDurationInto(&cfg.GitTimeout, v)
+		},
+	},
+	{
+		name:   "nerd-fonts",
+		usage:  "use Nerd Font glyphs (true/false)",
+		isBool: true,
+		apply: func(cfg *Config, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			cfg.Display.NerdFonts = boolPtr(b)
+			return nil
+		},
+	},
+	{
+		name:  "compact-width",
+		usage: "truncate segments when total width exceeds this",
+		apply: func(cfg *Config, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return err
+			}
+			if n <= 0 {
+				return fmt.Errorf("must be positive, got %d", n)
+			}
+			cfg.Display.CompactWidth = n
+			return nil
+		},
+	},
+}
+
+// BindFlags registers a flag for every override on fs. Parsed values are
+// written into cfg, which should start empty and be passed to LoadSources as
+// the top override layer.
+func BindFlags(fs *flag.FlagSet, cfg *Config) {
+	for _, o := range overrides {
+		apply := o.apply
+		fn := func(v string) error { return apply(cfg, v) }
+		if o.isBool {
+			fs.BoolFunc(o.name, o.usage, fn)
+		} else {
+			fs.Func(o.name, o.usage, fn)
+		}
+	}
+}
+
+// FromEnv builds an override Config from CONDUCTOR_POWERLINE_* variables.
+// Invalid values are skipped and reported together in the returned error;
+// valid ones are still applied.
+func FromEnv(getenv func(string) string) (Config, error) {
+	var cfg Config
+	var errs []error
+	for _, o := range overrides {
+		name := EnvName(o.name)
+		v := getenv(name)
+		if v == "" {
+			continue
+		}
+		if err := o.apply(&cfg, v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return cfg, errors.Join(errs...)
+}
+
+// EnvName returns the environment variable name for a flag name.
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// splitList splits a comma-separated list, trimming blanks.
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func setEnabled(cfg *Config, names []string, enabled bool) {
+	if cfg.Segments == nil {
+		cfg.Segments = make(map[string]SegmentConfig)
+	}
+	for _, name := range names {
+		cfg.Segments[name] = SegmentConfig{Enabled: boolPtr(enabled)}
+	}
+}
+
+func parseDurationInto(d *Duration, v string) error {
+	dur, err := time.ParseDuration(v)
+	if err != nil {
+		return err
+	}
+	if dur <= 0 {
+		return fmt.Errorf("must be positive, got %s", v)
+	}
+	d.Duration = dur
+	return nil
+}
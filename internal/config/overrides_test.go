@@ -0,0 +1,158 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func parseTestFlags(t *testing.T, args ...string) (Config, error) {
+	t.Helper()
+	var cfg Config
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	BindFlags(fs, &cfg)
+	err := fs.Parse(args)
+	return cfg, err
+}
+
+func TestBindFlags(t *testing.T) {
+	cfg, err := parseTestFlags(t,
+		"--theme", "nord",
+		"--segments", "model, block",
+		"--disable", "weekly,context",
+		"--enable", "git",
+		"--api-timeout", "2s",
+		"--cache-ttl", "90s",
+		"--git-timeout", "250ms",
+		"--nerd-fonts=false",
+		"--compact-width", "80",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Theme != "nord" {
+		t.Errorf("expected theme 'nord', got %q", cfg.Theme)
+	}
+	if len(cfg.SegmentOrder) != 2 || cfg.SegmentOrder[0] != "model" || cfg.SegmentOrder[1] != "block" {
+		t.Errorf("expected order [model block], got %v", cfg.SegmentOrder)
+	}
+	if cfg.Segments["weekly"].IsEnabled() || cfg.Segments["context"].IsEnabled() {
+		t.Error("expected weekly and context disabled")
+	}
+	if !cfg.Segments["git"].IsEnabled() {
+		t.Error("expected git enabled")
+	}
+	if cfg.APITimeout.Duration != 2*time.Second {
+		t.Errorf("expected APITimeout 2s, got %v", cfg.APITimeout.Duration)
+	}
+	if cfg.CacheTTL.Duration != 90*time.Second {
+		t.Errorf("expected CacheTTL 90s, got %v", cfg.CacheTTL.Duration)
+	}
+	if cfg.GitTimeout.Duration != 250*time.Millisecond {
+		t.Errorf("expected GitTimeout 250ms, got %v", cfg.GitTimeout.Duration)
+	}
+	if cfg.Display.NerdFontsEnabled() {
+		t.Error("expected NerdFonts disabled")
+	}
+	if cfg.Display.CompactWidth != 80 {
+		t.Errorf("expected CompactWidth 80, got %d", cfg.Display.CompactWidth)
+	}
+}
+
+func TestBindFlagsBareBool(t *testing.T) {
+	cfg, err := parseTestFlags(t, "--nerd-fonts")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Display.NerdFonts == nil || !*cfg.Display.NerdFonts {
+		t.Error("expected bare --nerd-fonts to enable Nerd Fonts")
+	}
+}
+
+func TestBindFlagsInvalid(t *testing.T) {
+	if _, err := parseTestFlags(t, "--api-timeout", "soon"); err == nil {
+		t.Error("expected error for invalid duration")
+	}
+	if _, err := parseTestFlags(t, "--compact-width", "-5"); err == nil {
+		t.Error("expected error for negative width")
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	env := map[string]string{
+		"CONDUCTOR_POWERLINE_THEME":     "gruvbox",
+		"CONDUCTOR_POWERLINE_SEGMENTS":  "directory,model",
+		"CONDUCTOR_POWERLINE_CACHE_TTL": "5m",
+	}
+	cfg, err := FromEnv(func(k string) string { return env[k] })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Theme != "gruvbox" {
+		t.Errorf("expected theme 'gruvbox', got %q", cfg.Theme)
+	}
+	if len(cfg.SegmentOrder) != 2 {
+		t.Errorf("expected 2 segments, got %v", cfg.SegmentOrder)
+	}
+	if cfg.CacheTTL.Duration != 5*time.Minute {
+		t.Errorf("expected CacheTTL 5m, got %v", cfg.CacheTTL.Duration)
+	}
+}
+
+func TestFromEnvInvalidValueSkipped(t *testing.T) {
+	env := map[string]string{
+		"CONDUCTOR_POWERLINE_THEME":       "nord",
+		"CONDUCTOR_POWERLINE_API_TIMEOUT": "later",
+	}
+	cfg, err := FromEnv(func(k string) string { return env[k] })
+	if err == nil {
+		t.Error("expected error for invalid API timeout")
+	}
+	if cfg.Theme != "nord" {
+		t.Errorf("expected valid values still applied, got theme %q", cfg.Theme)
+	}
+	if cfg.APITimeout.Duration != 0 {
+		t.Errorf("expected invalid timeout left unset, got %v", cfg.APITimeout.Duration)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("api-timeout"); got != "CONDUCTOR_POWERLINE_API_TIMEOUT" {
+		t.Errorf("EnvName = %q", got)
+	}
+}
+
+func TestLoadSourcesPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "user.json")
+	projectPath := filepath.Join(dir, "project.json")
+	if err := os.WriteFile(userPath, []byte(`{"theme":"light","cacheTTL":"2m"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(projectPath, []byte(`{"theme":"nord","segments":{"model":{"enabled":false}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	env := Config{Theme: "gruvbox"}
+	flags := Config{Theme: "rose-pine", Segments: map[string]SegmentConfig{"model": {Enabled: boolPtr(true)}}}
+
+	cfg, _ := LoadSources(Sources{
+		UserPath:     userPath,
+		ProjectPaths: []string{projectPath},
+		Overrides:    []Config{env, flags},
+	})
+
+	if cfg.Theme != "rose-pine" {
+		t.Errorf("expected flag theme to win, got %q", cfg.Theme)
+	}
+	if !cfg.Segments["model"].IsEnabled() {
+		t.Error("expected flag to re-enable model over project config")
+	}
+	if cfg.CacheTTL.Duration != 2*time.Minute {
+		t.Errorf("expected user CacheTTL preserved, got %v", cfg.CacheTTL.Duration)
+	}
+}
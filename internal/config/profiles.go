@@ -0,0 +1,153 @@
+package config
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Profile is a partial config applied only when its matchers all hold.
+// A profile with no matchers always applies.
+type Profile struct {
+	Name   string       `json:"name,omitempty" desc:"Label shown in debug logs."`
+	When   ProfileMatch `json:"when" desc:"Conditions that must all match for the profile to apply."`
+	Config Config       `json:"config" desc:"Partial config merged on top of the file layers when the profile matches."`
+}
+
+// ProfileMatch lists the conditions for a Profile. Empty fields are ignored.
+type ProfileMatch struct {
+	Workspace  string            `json:"workspace,omitempty" desc:"Glob matched against the workspace path; * stays within a directory, ** crosses directories, ~/ expands to the home directory."`
+	Branch     string            `json:"branch,omitempty" desc:"Regular expression matched against the current git branch."`
+	RemoteHost string            `json:"remoteHost,omitempty" desc:"Host of the origin remote, e.g. github.com."`
+	Model      string            `json:"model,omitempty" desc:"Prefix of the model ID, e.g. claude-opus."`
+	Env        map[string]string `json:"env,omitempty" desc:"Environment variables that must equal the given value; \"*\" matches any non-empty value."`
+}
+
+// MatchContext supplies the facts profiles are matched against. Branch and
+// RemoteURL are called lazily so git is only run when a profile asks for it.
+type MatchContext struct {
+	Workspace string
+	ModelID   string
+	Branch    func() string
+	RemoteURL func() string
+	Getenv    func(string) string
+}
+
+// ApplyProfiles merges every matching profile onto cfg in declaration order,
+// so later profiles win, and returns the names of those applied. Invalid
+// branch patterns never match.
+func ApplyProfiles(cfg Config, ctx MatchContext) (Config, []string) {
+	var applied []string
+	for _, p := range cfg.Profiles {
+		if !p.When.Matches(ctx) {
+			continue
+		}
+		layer := p.Config
+		layer.Profiles = nil // nested profiles are not evaluated
+		cfg = MergeConfig(cfg, layer)
+		applied = append(applied, p.Name)
+	}
+	return cfg, applied
+}
+
+// Matches reports whether every non-empty condition in m holds for ctx.
+func (m ProfileMatch) Matches(ctx MatchContext) bool {
+	if m.Workspace != "" && !matchWorkspace(m.Workspace, ctx.Workspace) {
+		return false
+	}
+	if m.Model != "" && !strings.HasPrefix(ctx.ModelID, m.Model) {
+		return false
+	}
+	for name, want := range m.Env {
+		if ctx.Getenv == nil {
+			return false
+		}
+		got := ctx.Getenv(name)
+		if want == "*" {
+			if got == "" {
+				return false
+			}
+		} else if got != want {
+			return false
+		}
+	}
+	if m.Branch != "" {
+		re, err := regexp.Compile(m.Branch)
+		if err != nil || ctx.Branch == nil || !re.MatchString(ctx.Branch()) {
+			return false
+		}
+	}
+	if m.RemoteHost != "" {
+		if ctx.RemoteURL == nil || !strings.EqualFold(remoteHost(ctx.RemoteURL()), m.RemoteHost) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchWorkspace matches a workspace glob against path. Both sides use
+// forward slashes so patterns are portable.
+func matchWorkspace(pattern, path string) bool {
+	if path == "" {
+		return false
+	}
+	if strings.HasPrefix(pattern, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			pattern = filepath.Join(home, pattern[2:])
+		}
+	}
+	re, err := globRegexp(filepath.ToSlash(pattern))
+	if err != nil {
+		return false
+	}
+	return re.MatchString(filepath.ToSlash(filepath.Clean(path)))
+}
+
+// globRegexp converts a glob to an anchored regular expression: ** matches
+// anything, * and ? match within a single path element.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// remoteHost extracts the host from a git remote URL, accepting both URL
+// forms (https://host/..., ssh://git@host:22/...) and scp-like git@host:path.
+func remoteHost(remote string) string {
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return ""
+	}
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return ""
+		}
+		return u.Hostname()
+	}
+	if at := strings.Index(remote, "@"); at >= 0 {
+		remote = remote[at+1:]
+	}
+	if colon := strings.Index(remote, ":"); colon >= 0 {
+		return remote[:colon]
+	}
+	return ""
+}
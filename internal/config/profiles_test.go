@@ -0,0 +1,172 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProfileMatchWorkspaceGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/work/*", "/work/api", true},
+		{"/work/*", "/work/api/sub", false},
+		{"/work/**", "/work/api/sub", true},
+		{"/work/ap?", "/work/api", true},
+		{"/work/*", "/home/api", false},
+		{"/work/*", "", false},
+	}
+	for _, tt := range tests {
+		m := ProfileMatch{Workspace: tt.pattern}
+		if got := m.Matches(MatchContext{Workspace: tt.path}); got != tt.want {
+			t.Errorf("Workspace %q vs %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestProfileMatchWorkspaceHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	m := ProfileMatch{Workspace: "~/sandbox/**"}
+	if !m.Matches(MatchContext{Workspace: filepath.Join(home, "sandbox", "toy")}) {
+		t.Error("expected ~/ to expand to the home directory")
+	}
+}
+
+func TestProfileMatchModelPrefix(t *testing.T) {
+	m := ProfileMatch{Model: "claude-opus"}
+	if !m.Matches(MatchContext{ModelID: "claude-opus-4-6"}) {
+		t.Error("expected prefix match")
+	}
+	if m.Matches(MatchContext{ModelID: "claude-sonnet-4-6"}) {
+		t.Error("expected no match for a different model")
+	}
+}
+
+func TestProfileMatchEnv(t *testing.T) {
+	env := map[string]string{"CI": "true", "SANDBOX": "1"}
+	ctx := MatchContext{Getenv: func(k string) string { return env[k] }}
+
+	if !(ProfileMatch{Env: map[string]string{"CI": "true"}}).Matches(ctx) {
+		t.Error("expected exact env match")
+	}
+	if (ProfileMatch{Env: map[string]string{"CI": "false"}}).Matches(ctx) {
+		t.Error("expected mismatch on different value")
+	}
+	if !(ProfileMatch{Env: map[string]string{"SANDBOX": "*"}}).Matches(ctx) {
+		t.Error("expected * to match any non-empty value")
+	}
+	if (ProfileMatch{Env: map[string]string{"UNSET": "*"}}).Matches(ctx) {
+		t.Error("expected * not to match an unset variable")
+	}
+}
+
+func TestProfileMatchBranchIsLazy(t *testing.T) {
+	calls := 0
+	ctx := MatchContext{
+		ModelID: "claude-sonnet-4-6",
+		Branch: func() string {
+			calls++
+			return "release/1.2"
+		},
+	}
+
+	if !(ProfileMatch{Branch: `^release/`}).Matches(ctx) {
+		t.Error("expected branch regex to match")
+	}
+	if (ProfileMatch{Branch: `[`}).Matches(ctx) {
+		t.Error("expected invalid regex never to match")
+	}
+	calls = 0
+	if (ProfileMatch{Model: "claude-opus", Branch: `.*`}).Matches(ctx) {
+		t.Error("expected model mismatch")
+	}
+	if calls != 0 {
+		t.Errorf("expected branch not to be read after an earlier mismatch, got %d calls", calls)
+	}
+}
+
+func TestProfileMatchRemoteHost(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{"https://github.com/owner/repo.git", "github.com"},
+		{"git@gitlab.example.com:team/repo.git", "gitlab.example.com"},
+		{"ssh://git@git.corp:2222/team/repo.git", "git.corp"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := remoteHost(tt.remote); got != tt.want {
+			t.Errorf("remoteHost(%q) = %q, want %q", tt.remote, got, tt.want)
+		}
+	}
+
+	m := ProfileMatch{RemoteHost: "GitHub.com"}
+	ctx := MatchContext{RemoteURL: func() string { return "git@github.com:o/r.git" }}
+	if !m.Matches(ctx) {
+		t.Error("expected case-insensitive host match")
+	}
+}
+
+func TestApplyProfilesInOrder(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Profiles = []Profile{
+		{Name: "opus", When: ProfileMatch{Model: "claude-opus"}, Config: Config{Theme: "nord"}},
+		{Name: "never", When: ProfileMatch{Model: "gpt"}, Config: Config{Theme: "light"}},
+		{Name: "always", Config: Config{Display: DisplayConfig{CompactWidth: 80}}},
+		{Name: "later", When: ProfileMatch{Model: "claude"}, Config: Config{Theme: "gruvbox"}},
+	}
+
+	got, applied := ApplyProfiles(cfg, MatchContext{ModelID: "claude-opus-4-6"})
+	if got.Theme != "gruvbox" {
+		t.Errorf("expected later profile to win, got theme %q", got.Theme)
+	}
+	if got.Display.CompactWidth != 80 {
+		t.Errorf("expected unconditional profile to apply, got %d", got.Display.CompactWidth)
+	}
+	want := []string{"opus", "always", "later"}
+	if len(applied) != len(want) {
+		t.Fatalf("applied = %v, want %v", applied, want)
+	}
+	for i := range want {
+		if applied[i] != want[i] {
+			t.Errorf("applied[%d] = %q, want %q", i, applied[i], want[i])
+		}
+	}
+}
+
+func TestLoadSourcesProfilesBeforeOverrides(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.json")
+	project := filepath.Join(dir, "project.yaml")
+	writeFile(t, user, `{"profiles": [{"name": "user", "when": {"model": "claude-opus"}, "config": {"theme": "nord", "display": {"compactWidth": 60}}}]}`)
+	writeFile(t, project, "theme: light\nprofiles:\n  - name: project\n    when:\n      workspace: \"/repo/**\"\n    config:\n      theme: gruvbox\n")
+
+	cfg, _ := LoadSources(Sources{
+		UserPath:       user,
+		ProjectPaths:   []string{project},
+		ProjectTrusted: true,
+		Match:          &MatchContext{Workspace: "/repo/pkg", ModelID: "claude-opus-4-6"},
+		Overrides:      []Config{{Display: DisplayConfig{CompactWidth: 120}}},
+	})
+
+	if cfg.Theme != "gruvbox" {
+		t.Errorf("expected project profile to beat file layers, got theme %q", cfg.Theme)
+	}
+	if cfg.Display.CompactWidth != 120 {
+		t.Errorf("expected override to beat profiles, got %d", cfg.Display.CompactWidth)
+	}
+	if len(cfg.Profiles) != 2 {
+		t.Errorf("expected profiles from both files to accumulate, got %d", len(cfg.Profiles))
+	}
+
+	noMatch, _ := LoadSources(Sources{UserPath: user, ProjectPaths: []string{project}, ProjectTrusted: true})
+	if noMatch.Theme != "light" {
+		t.Errorf("expected profiles skipped without a match context, got theme %q", noMatch.Theme)
+	}
+}
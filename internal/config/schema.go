@@ -0,0 +1,192 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+)
+
+// schemaID is the $id advertised in the generated schema.
+const schemaID = "https://github.com/rbarcante/conductor-powerline/config.schema.json"
+
+// schemaProvider is implemented by types whose JSON form differs from their
+// Go shape (e.g. Duration, which marshals to a string).
+type schemaProvider interface {
+	JSONSchema() map[string]any
+}
+
+// Schema returns a JSON Schema (draft 2020-12) describing Config, generated
+// from its struct tags. Field descriptions come from the `desc` tag. Known
+// theme and segment names are listed so editors can autocomplete them.
+func Schema() map[string]any {
+	g := &schemaGen{defs: map[string]any{}}
+	root := g.typeSchema(reflect.TypeOf(Config{}))
+
+	cfgDef := g.defs["Config"].(map[string]any)
+	props := cfgDef["properties"].(map[string]any)
+
+	themeProp := props["theme"].(map[string]any)
+	themeProp["enum"] = themes.Names()
+
+	segNames := SegmentNames()
+	segProps := map[string]any{}
+	for _, name := range segNames {
+		segProps[name] = map[string]any{"$ref": "#/$defs/SegmentConfig"}
+	}
+	segmentsProp := props["segments"].(map[string]any)
+	segmentsProp["properties"] = segProps
+	// Any other name is a custom segment and must say what type it is.
+	segmentsProp["additionalProperties"] = map[string]any{
+		"allOf": []any{
+			map[string]any{"$ref": "#/$defs/SegmentConfig"},
+			map[string]any{"required": []string{"type"}},
+		},
+	}
+	segConfigProps := g.defs["SegmentConfig"].(map[string]any)["properties"].(map[string]any)
+	segConfigProps["type"].(map[string]any)["enum"] = SegmentTypes
+
+	// segmentOrder is either a full list or an OrderOps object (see
+	// Config.UnmarshalJSON), which reflection alone cannot express. Names
+	// of custom segments are allowed alongside the built-in ones, which are
+	// listed for autocompletion.
+	segName := map[string]any{"type": "string", "anyOf": []any{
+		map[string]any{"enum": segNames},
+		map[string]any{"pattern": "^[A-Za-z0-9_-]+$"},
+	}}
+	orderProp := props["segmentOrder"].(map[string]any)
+	props["segmentOrder"] = map[string]any{
+		"description": orderProp["description"],
+		"oneOf": []any{
+			map[string]any{"type": "array", "items": segName},
+			g.typeSchema(reflect.TypeOf(OrderOps{})),
+		},
+	}
+	opsProps := g.defs["OrderOps"].(map[string]any)["properties"].(map[string]any)
+	for _, key := range []string{"remove", "prepend", "append"} {
+		opsProps[key].(map[string]any)["items"] = segName
+	}
+	for _, key := range []string{"before", "after"} {
+		prop := opsProps[key].(map[string]any)
+		prop["propertyNames"] = segName
+		prop["additionalProperties"] = segName
+	}
+
+	keysProp := props["projectKeys"].(map[string]any)
+	keysProp["items"] = map[string]any{"type": "string", "enum": configKeys()}
+
+	thresholdsProp := props["thresholds"].(map[string]any)
+	thresholdsProp["propertyNames"] = map[string]any{"enum": thresholdNames}
+
+	schema := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     schemaID,
+		"title":   "conductor-powerline configuration",
+		"$defs":   g.defs,
+	}
+	for k, v := range root {
+		schema[k] = v
+	}
+	return schema
+}
+
+// SegmentNames returns the sorted names of all built-in segments, taken from
+// the default config so the two cannot drift apart.
+func SegmentNames() []string {
+	defaults := DefaultConfig().Segments
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SegmentTypes lists the values accepted for a custom segment's type.
+var SegmentTypes = []string{"hook_field"}
+
+// thresholdNames lists the keys accepted under "thresholds".
+var thresholdNames = []string{"block", "context", "diff", "opus", "session", "sonnet", "weekly"}
+
+// JSONSchema describes Duration as a Go duration string.
+func (Duration) JSONSchema() map[string]any {
+	return map[string]any{
+		"type":        "string",
+		"pattern":     `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`,
+		"description": `Go duration string, e.g. "500ms", "5s", "1m30s".`,
+	}
+}
+
+// schemaGen accumulates named struct definitions so recursive or shared
+// types are emitted once under $defs.
+type schemaGen struct {
+	defs map[string]any
+}
+
+var schemaProviderType = reflect.TypeOf((*schemaProvider)(nil)).Elem()
+
+func (g *schemaGen) typeSchema(t reflect.Type) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		return g.typeSchema(t.Elem())
+	}
+	if t.Implements(schemaProviderType) {
+		return reflect.Zero(t).Interface().(schemaProvider).JSONSchema()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		return g.structRef(t)
+	}
+	// interface{} and anything else accept any JSON value.
+	return map[string]any{}
+}
+
+// structRef registers t under $defs (once) and returns a $ref to it.
+func (g *schemaGen) structRef(t reflect.Type) map[string]any {
+	name := t.Name()
+	ref := map[string]any{"$ref": "#/$defs/" + name}
+	if _, ok := g.defs[name]; ok {
+		return ref
+	}
+	def := map[string]any{"type": "object", "additionalProperties": false}
+	g.defs[name] = def // registered before recursing so cycles terminate
+
+	props := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key := strings.Split(f.Tag.Get("json"), ",")[0]
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = f.Name
+		}
+		prop := g.typeSchema(f.Type)
+		if desc := f.Tag.Get("desc"); desc != "" {
+			// A $ref cannot carry siblings in older drafts; wrap it.
+			if _, isRef := prop["$ref"]; isRef {
+				prop = map[string]any{"allOf": []any{prop}}
+			}
+			prop["description"] = desc
+		}
+		props[key] = prop
+	}
+	def["properties"] = props
+	return ref
+}
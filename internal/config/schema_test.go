@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchemaIsValidJSON(t *testing.T) {
+	b, err := json.Marshal(Schema())
+	if err != nil {
+		t.Fatalf("schema does not marshal: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("schema does not round-trip: %v", err)
+	}
+	if decoded["$ref"] != "#/$defs/Config" {
+		t.Errorf("expected root $ref to Config, got %v", decoded["$ref"])
+	}
+}
+
+func TestSchemaConfigProperties(t *testing.T) {
+	defs := Schema()["$defs"].(map[string]any)
+	cfg := defs["Config"].(map[string]any)
+	props := cfg["properties"].(map[string]any)
+
+	for _, key := range []string{"$schema", "theme", "display", "segments", "segmentOrder", "apiTimeout", "cacheTTL", "thresholds"} {
+		if _, ok := props[key]; !ok {
+			t.Errorf("expected property %q in schema", key)
+		}
+	}
+
+	timeout := props["apiTimeout"].(map[string]any)
+	if timeout["type"] != "string" || timeout["pattern"] == nil {
+		t.Errorf("expected Duration as pattern-constrained string, got %v", timeout)
+	}
+
+	theme := props["theme"].(map[string]any)
+	enum, ok := theme["enum"].([]string)
+	if !ok || len(enum) == 0 {
+		t.Errorf("expected theme enum, got %v", theme["enum"])
+	}
+
+	segments := props["segments"].(map[string]any)
+	segProps := segments["properties"].(map[string]any)
+	for _, name := range SegmentNames() {
+		if _, ok := segProps[name]; !ok {
+			t.Errorf("expected segment %q in schema", name)
+		}
+	}
+	custom, ok := segments["additionalProperties"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected custom segments schema, got %v", segments["additionalProperties"])
+	}
+	required := custom["allOf"].([]any)[1].(map[string]any)["required"].([]string)
+	if len(required) != 1 || required[0] != "type" {
+		t.Errorf("expected custom segments to require a type, got %v", required)
+	}
+}
+
+func TestSchemaSegmentOrderAcceptsListOrOps(t *testing.T) {
+	defs := Schema()["$defs"].(map[string]any)
+	props := defs["Config"].(map[string]any)["properties"].(map[string]any)
+
+	order := props["segmentOrder"].(map[string]any)
+	oneOf, ok := order["oneOf"].([]any)
+	if !ok || len(oneOf) != 2 {
+		t.Fatalf("expected segmentOrder oneOf list/ops, got %v", order)
+	}
+	if oneOf[1].(map[string]any)["$ref"] != "#/$defs/OrderOps" {
+		t.Errorf("expected OrderOps ref, got %v", oneOf[1])
+	}
+	if _, ok := defs["OrderOps"]; !ok {
+		t.Error("expected OrderOps in $defs")
+	}
+}
+
+func TestSegmentNamesMatchDefaults(t *testing.T) {
+	names := SegmentNames()
+	if len(names) != len(DefaultConfig().Segments) {
+		t.Errorf("expected %d names, got %d", len(DefaultConfig().Segments), len(names))
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] > names[i] {
+			t.Errorf("expected sorted names, got %v", names)
+		}
+	}
+}
@@ -0,0 +1,478 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseTOML decodes a TOML document into plain Go values (map[string]any,
+// []any, string, int64, float64, bool).
+//
+// Supported: key/value pairs with bare, quoted and dotted keys, [tables],
+// [[arrays of tables]], basic and literal strings (including multi-line),
+// integers, floats, booleans, arrays and inline tables. Dates and times are
+// kept as strings since no config field uses them.
+func parseTOML(data []byte) (map[string]any, error) {
+	p := &tomlParser{src: strings.ReplaceAll(string(data), "\r\n", "\n"), line: 1}
+	root := map[string]any{}
+	current := root
+
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return root, nil
+		}
+		var err error
+		if p.peek() == '[' {
+			current, err = p.table(root)
+		} else {
+			err = p.keyValue(current)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type tomlParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *tomlParser) eof() bool  { return p.pos >= len(p.src) }
+func (p *tomlParser) peek() byte { return p.src[p.pos] }
+
+func (p *tomlParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("toml: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) advance() {
+	if p.src[p.pos] == '\n' {
+		p.line++
+	}
+	p.pos++
+}
+
+// skipSpace skips spaces and tabs on the current line.
+func (p *tomlParser) skipSpace() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skipComment skips a # comment up to (not including) the newline.
+func (p *tomlParser) skipComment() {
+	if !p.eof() && p.peek() == '#' {
+		for !p.eof() && p.peek() != '\n' {
+			p.pos++
+		}
+	}
+}
+
+// skipBlank skips whitespace, newlines and comments.
+func (p *tomlParser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\n':
+			p.advance()
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine requires only whitespace or a comment before the next newline.
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace()
+	p.skipComment()
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return p.errorf("expected newline, found %q", p.peek())
+	}
+	p.advance()
+	return nil
+}
+
+// table parses a [table] or [[array]] header and returns the map that
+// subsequent key/value pairs belong to.
+func (p *tomlParser) table(root map[string]any) (map[string]any, error) {
+	p.pos++ // [
+	array := !p.eof() && p.peek() == '['
+	if array {
+		p.pos++
+	}
+	p.skipSpace()
+	keys, err := p.keyPath()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.src[p.pos:], closing) {
+		return nil, p.errorf("expected %q to close table header", closing)
+	}
+	p.pos += len(closing)
+
+	parent, err := p.descend(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := keys[len(keys)-1]
+	if array {
+		existing, ok := parent[last]
+		if !ok {
+			existing = []any{}
+		}
+		arr, ok := existing.([]any)
+		if !ok {
+			return nil, p.errorf("key %q is not an array of tables", last)
+		}
+		tbl := map[string]any{}
+		parent[last] = append(arr, tbl)
+		return tbl, nil
+	}
+	if existing, ok := parent[last]; ok {
+		tbl, ok := existing.(map[string]any)
+		if !ok {
+			return nil, p.errorf("key %q is already defined as a value", last)
+		}
+		return tbl, nil
+	}
+	tbl := map[string]any{}
+	parent[last] = tbl
+	return tbl, nil
+}
+
+// descend walks keys from m, creating intermediate tables. When a key holds an
+// array of tables, the last element is used, as TOML specifies.
+func (p *tomlParser) descend(m map[string]any, keys []string) (map[string]any, error) {
+	for _, k := range keys {
+		switch v := m[k].(type) {
+		case nil:
+			next := map[string]any{}
+			m[k] = next
+			m = next
+		case map[string]any:
+			m = v
+		case []any:
+			if len(v) == 0 {
+				return nil, p.errorf("key %q is an empty array", k)
+			}
+			tbl, ok := v[len(v)-1].(map[string]any)
+			if !ok {
+				return nil, p.errorf("key %q is not a table", k)
+			}
+			m = tbl
+		default:
+			return nil, p.errorf("key %q is already defined as a value", k)
+		}
+	}
+	return m, nil
+}
+
+// keyValue parses "key = value" into m.
+func (p *tomlParser) keyValue(m map[string]any) error {
+	keys, err := p.keyPath()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if p.eof() || p.peek() != '=' {
+		return p.errorf("expected '=' after key %q", strings.Join(keys, "."))
+	}
+	p.pos++
+	p.skipSpace()
+	v, err := p.value()
+	if err != nil {
+		return err
+	}
+	parent, err := p.descend(m, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, dup := parent[last]; dup {
+		return p.errorf("duplicate key %q", last)
+	}
+	parent[last] = v
+	return nil
+}
+
+// keyPath parses a possibly dotted key made of bare or quoted parts.
+func (p *tomlParser) keyPath() ([]string, error) {
+	var keys []string
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil, p.errorf("expected key")
+		}
+		var k string
+		switch c := p.peek(); {
+		case c == '"' || c == '\'':
+			s, err := p.stringValue()
+			if err != nil {
+				return nil, err
+			}
+			k = s
+		case isTOMLBareKeyChar(c):
+			start := p.pos
+			for !p.eof() && isTOMLBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			k = p.src[start:p.pos]
+		default:
+			return nil, p.errorf("invalid key character %q", c)
+		}
+		keys = append(keys, k)
+		p.skipSpace()
+		if p.eof() || p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func isTOMLBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) value() (any, error) {
+	if p.eof() {
+		return nil, p.errorf("expected value")
+	}
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.stringValue()
+	case c == '[':
+		return p.array()
+	case c == '{':
+		return p.inlineTable()
+	case strings.HasPrefix(p.src[p.pos:], "true"):
+		p.pos += 4
+		return true, nil
+	case strings.HasPrefix(p.src[p.pos:], "false"):
+		p.pos += 5
+		return false, nil
+	}
+	return p.scalar()
+}
+
+// scalar parses numbers, and keeps dates/times as strings.
+func (p *tomlParser) scalar() (any, error) {
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if c == ',' || c == ']' || c == '}' || c == '\n' || c == '#' {
+			break
+		}
+		p.pos++
+	}
+	raw := strings.TrimSpace(p.src[start:p.pos])
+	if raw == "" {
+		return nil, p.errorf("expected value")
+	}
+	clean := strings.ReplaceAll(raw, "_", "")
+	if i, err := strconv.ParseInt(clean, 0, 64); err == nil {
+		return i, nil
+	}
+	switch clean {
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		return nil, p.errorf("special float %q is not supported", raw)
+	}
+	if f, err := strconv.ParseFloat(clean, 64); err == nil {
+		return f, nil
+	}
+	if len(raw) >= 8 && (raw[4] == '-' || raw[2] == ':') {
+		return raw, nil
+	}
+	return nil, p.errorf("invalid value %q", raw)
+}
+
+func (p *tomlParser) array() ([]any, error) {
+	p.pos++ // [
+	arr := []any{}
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return arr, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+		p.skipBlank()
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (p *tomlParser) inlineTable() (map[string]any, error) {
+	p.pos++ // {
+	m := map[string]any{}
+	p.skipSpace()
+	if !p.eof() && p.peek() == '}' {
+		p.pos++
+		return m, nil
+	}
+	for {
+		if err := p.keyValue(m); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.eof() {
+			return nil, p.errorf("unterminated inline table")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return m, nil
+		default:
+			return nil, p.errorf("expected ',' or '}' in inline table")
+		}
+	}
+}
+
+// stringValue parses basic ("..."), literal ('...') and their multi-line
+// forms.
+func (p *tomlParser) stringValue() (string, error) {
+	q := p.peek()
+	multi := strings.HasPrefix(p.src[p.pos:], strings.Repeat(string(q), 3))
+	if multi {
+		return p.multilineString(q)
+	}
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.peek()
+		if c == q {
+			p.pos++
+			return b.String(), nil
+		}
+		if c == '\\' && q == '"' {
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+}
+
+func (p *tomlParser) multilineString(q byte) (string, error) {
+	delim := strings.Repeat(string(q), 3)
+	p.pos += 3
+	// A newline immediately after the opening delimiter is trimmed.
+	if !p.eof() && p.peek() == '\n' {
+		p.advance()
+	}
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated multi-line string")
+		}
+		if strings.HasPrefix(p.src[p.pos:], delim) {
+			p.pos += 3
+			return b.String(), nil
+		}
+		c := p.peek()
+		if c == '\\' && q == '"' {
+			// Line-ending backslash trims the newline and following whitespace.
+			rest := strings.TrimLeft(p.src[p.pos+1:], " \t")
+			if strings.HasPrefix(rest, "\n") {
+				p.pos++
+				p.skipBlankNoComment()
+				continue
+			}
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteByte(c)
+		p.advance()
+	}
+}
+
+// skipBlankNoComment skips whitespace and newlines only.
+func (p *tomlParser) skipBlankNoComment() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\n') {
+		p.advance()
+	}
+}
+
+// escape decodes a backslash escape sequence at pos into b.
+func (p *tomlParser) escape(b *strings.Builder) error {
+	p.pos++ // backslash
+	if p.eof() {
+		return p.errorf("unterminated escape")
+	}
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case 'e':
+		b.WriteByte(0x1b)
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.src) {
+			return p.errorf("short unicode escape")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid unicode escape")
+		}
+		b.WriteRune(rune(code))
+		p.pos += n
+	default:
+		return p.errorf("invalid escape \\%c", c)
+	}
+	return nil
+}
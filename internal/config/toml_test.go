@@ -0,0 +1,125 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOMLDocument(t *testing.T) {
+	src := `
+# user config
+theme = "nord"
+segmentOrder = [
+  "model",   # first
+  'block',
+]
+apiTimeout = "10s"
+trendThreshold = 2.5
+
+[display]
+nerdFonts = false
+compactWidth = 1_00
+
+[segments.git]
+enabled = false
+
+[thresholds]
+block = { warning = 50, critical = 90 }
+weekly.levels = [{ at = 85, color = "critical" }]
+`
+	got, err := parseTOML([]byte(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]any{
+		"theme":          "nord",
+		"segmentOrder":   []any{"model", "block"},
+		"apiTimeout":     "10s",
+		"trendThreshold": 2.5,
+		"display": map[string]any{
+			"nerdFonts":    false,
+			"compactWidth": int64(100),
+		},
+		"segments": map[string]any{
+			"git": map[string]any{"enabled": false},
+		},
+		"thresholds": map[string]any{
+			"block": map[string]any{"warning": int64(50), "critical": int64(90)},
+			"weekly": map[string]any{
+				"levels": []any{map[string]any{"at": int64(85), "color": "critical"}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTOML mismatch\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestParseTOMLArrayOfTables(t *testing.T) {
+	src := `
+[[items]]
+name = "a"
+
+[[items]]
+name = "b"
+[items.sub]
+x = 1
+`
+	got, err := parseTOML([]byte(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	items := got["items"].([]any)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	second := items[1].(map[string]any)
+	if second["name"] != "b" {
+		t.Errorf("expected second name b, got %v", second["name"])
+	}
+	if sub, ok := second["sub"].(map[string]any); !ok || sub["x"] != int64(1) {
+		t.Errorf("expected [items.sub] to attach to last item, got %#v", second)
+	}
+}
+
+func TestParseTOMLStrings(t *testing.T) {
+	src := `a = "tab\tquote\"\u00e9"
+b = 'C:\path'
+c = """
+line1
+line2"""
+d = """\
+    trimmed"""
+`
+	got, err := parseTOML([]byte(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]any{
+		"a": "tab\tquote\"é",
+		"b": `C:\path`,
+		"c": "line1\nline2",
+		"d": "trimmed",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTOML mismatch\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []string{
+		`a = 1` + "\n" + `a = 2`,
+		`a = "unterminated`,
+		`a = [1, 2`,
+		`a 1`,
+		`a = 1 2`,
+		`[table`,
+		`a = nonsense`,
+		"a = 1\n[a]",
+	}
+	for _, src := range tests {
+		if _, err := parseTOML([]byte(src)); err == nil {
+			t.Errorf("expected error for %q", src)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/rbarcante/conductor-powerline/internal/atomicfile"
+)
+
+// TrustFileName is the trust store's file name inside the Claude config
+// directory.
+const TrustFileName = "conductor-powerline.trust.json"
+
+// DefaultProjectKeys lists the top-level keys an untrusted project config
+// may set. They only change how the statusline looks; anything that affects
+// network use or could grow more powerful (apiTimeout, cacheTTL, profiles)
+// needs `conductor-powerline allow`. Within segments, an untrusted config may
+// only switch segments on and off; see restrictSegments.
+var DefaultProjectKeys = []string{"display", "segmentOrder", "segments", "theme", "thresholds"}
+
+// userOnlyKeys may only be set in the user config, even by trusted projects.
+var userOnlyKeys = []string{"projectKeys"}
+
+// TrustStore records the workspaces whose project config the user approved.
+type TrustStore struct {
+	Workspaces map[string]TrustEntry `json:"workspaces"`
+}
+
+// TrustEntry pins an approved workspace to the config it had when approved.
+// Any change to its project config files, or to the files they extend,
+// revokes the trust.
+type TrustEntry struct {
+	Hash      string    `json:"hash"`
+	AllowedAt time.Time `json:"allowedAt"`
+}
+
+// LoadTrust reads the trust store at path. A missing file is an empty store.
+func LoadTrust(path string) (TrustStore, error) {
+	store := TrustStore{Workspaces: map[string]TrustEntry{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return store, err
+	}
+	if err := json.Unmarshal(data, &store); err != nil {
+		return store, err
+	}
+	if store.Workspaces == nil {
+		store.Workspaces = map[string]TrustEntry{}
+	}
+	return store, nil
+}
+
+// Save writes the store to path, replacing it atomically.
+func (s TrustStore) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(path, append(data, '\n'))
+}
+
+// Allow records workspace as trusted with the current contents of files and
+// of the files they extend.
+func (s *TrustStore) Allow(workspace string, files []string) error {
+	hash, err := HashFiles(TrustedFiles(files))
+	if err != nil {
+		return err
+	}
+	if s.Workspaces == nil {
+		s.Workspaces = map[string]TrustEntry{}
+	}
+	s.Workspaces[trustKey(workspace)] = TrustEntry{Hash: hash, AllowedAt: time.Now().UTC()}
+	return nil
+}
+
+// Trusted reports whether workspace was allowed and files, with the files
+// they extend, still hash to the recorded value.
+func (s TrustStore) Trusted(workspace string, files []string) bool {
+	entry, ok := s.Workspaces[trustKey(workspace)]
+	if !ok {
+		return false
+	}
+	hash, err := HashFiles(TrustedFiles(files))
+	return err == nil && hash == entry.Hash
+}
+
+// TrustedFiles returns files, each followed by its extends chain: every
+// file whose contents a trust decision covers. A broken chain contributes
+// the files read before the error; the layer is not applied until it is
+// fixed, which changes the list and so the hash.
+func TrustedFiles(files []string) []string {
+	var all []string
+	for _, path := range files {
+		_, chain, _ := loadLayer(path, nil, new([]Diagnostic))
+		if len(chain) == 0 {
+			chain = []string{path}
+		}
+		all = append(all, chain...)
+	}
+	return all
+}
+
+// HashFiles returns a SHA-256 over the paths and contents of files, in order.
+func HashFiles(files []string) (string, error) {
+	h := sha256.New()
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		_, _ = fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(path), len(data))
+		_, _ = h.Write(data)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func trustKey(workspace string) string {
+	if abs, err := filepath.Abs(workspace); err == nil {
+		return abs
+	}
+	return filepath.Clean(workspace)
+}
+
+// restrictKeys clears every top-level field of cfg whose JSON key is not
+// allowed and returns the keys that were set and cleared. $schema and
+// extends are always allowed; they do not change behavior on their own.
+// Profiles that survive are restricted the same way.
+func restrictKeys(cfg Config, allowed func(key string) bool) (Config, []string) {
+	var dropped []string
+	v := reflect.ValueOf(&cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key := strings.Split(f.Tag.Get("json"), ",")[0]
+		if key == "$schema" || key == "extends" || allowed(key) {
+			continue
+		}
+		if key == "segmentOrder" && len(cfg.orderOps) > 0 {
+			cfg.orderOps = nil
+			v.Field(i).SetZero()
+			dropped = append(dropped, key)
+			continue
+		}
+		if !v.Field(i).IsZero() {
+			v.Field(i).SetZero()
+			dropped = append(dropped, key)
+		}
+	}
+
+	if len(cfg.Profiles) > 0 {
+		profiles := make([]Profile, len(cfg.Profiles))
+		for i, p := range cfg.Profiles {
+			var sub []string
+			p.Config, sub = restrictKeys(p.Config, allowed)
+			for _, key := range sub {
+				dropped = append(dropped, fmt.Sprintf("profiles[%d].config.%s", i, key))
+			}
+			profiles[i] = p
+		}
+		cfg.Profiles = profiles
+	}
+	return cfg, dropped
+}
+
+// restrictSegments clears everything but enabled from the segments an
+// untrusted layer configures and returns the keys it cleared. Options can
+// point links at arbitrary URLs (git.issueURL, remote.hosts) and type
+// defines segments that print hook data, so both need trust; a custom
+// segment is dropped entirely. Profiles are restricted the same way.
+func restrictSegments(cfg Config) (Config, []string) {
+	var dropped []string
+	if len(cfg.Segments) > 0 {
+		segs := make(map[string]SegmentConfig, len(cfg.Segments))
+		for _, name := range sortedKeys(cfg.Segments) {
+			seg := cfg.Segments[name]
+			switch {
+			case seg.Type != "":
+				dropped = append(dropped, "segments."+name)
+				continue
+			case seg.Options != nil:
+				dropped = append(dropped, "segments."+name+".options")
+			}
+			segs[name] = SegmentConfig{Enabled: seg.Enabled}
+		}
+		cfg.Segments = segs
+	}
+
+	if len(cfg.Profiles) > 0 {
+		profiles := make([]Profile, len(cfg.Profiles))
+		for i, p := range cfg.Profiles {
+			var sub []string
+			p.Config, sub = restrictSegments(p.Config)
+			for _, key := range sub {
+				dropped = append(dropped, fmt.Sprintf("profiles[%d].config.%s", i, key))
+			}
+			profiles[i] = p
+		}
+		cfg.Profiles = profiles
+	}
+	return cfg, dropped
+}
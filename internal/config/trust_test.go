@@ -0,0 +1,203 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrustStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	workspace := filepath.Join(dir, "repo")
+	file := filepath.Join(workspace, ".conductor-powerline.json")
+	writeFile(t, file, `{"apiTimeout": "2s"}`)
+	storePath := filepath.Join(dir, "claude", TrustFileName)
+
+	store, err := LoadTrust(storePath)
+	if err != nil {
+		t.Fatalf("missing store should load empty, got %v", err)
+	}
+	if store.Trusted(workspace, []string{file}) {
+		t.Error("expected workspace untrusted before allow")
+	}
+	if err := store.Allow(workspace, []string{file}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(storePath); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := LoadTrust(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded.Trusted(workspace, []string{file}) {
+		t.Error("expected workspace trusted after allow")
+	}
+	if reloaded.Trusted(filepath.Join(dir, "other"), []string{file}) {
+		t.Error("expected trust to be per workspace")
+	}
+
+	writeFile(t, file, `{"apiTimeout": "1ms"}`)
+	if reloaded.Trusted(workspace, []string{file}) {
+		t.Error("expected editing the config to revoke trust")
+	}
+}
+
+func TestTrustCoversExtends(t *testing.T) {
+	dir := t.TempDir()
+	workspace := filepath.Join(dir, "repo")
+	file := filepath.Join(workspace, ".conductor-powerline.json")
+	shared := filepath.Join(dir, "shared", "powerline.json")
+	base := filepath.Join(dir, "shared", "base.json")
+	writeFile(t, file, `{"extends": "../shared/powerline.json"}`)
+	writeFile(t, shared, `{"extends": "base.json", "theme": "nord"}`)
+	writeFile(t, base, `{}`)
+
+	if got, want := TrustedFiles([]string{file}), []string{file, shared, base}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("TrustedFiles = %v, want %v", got, want)
+	}
+
+	var store TrustStore
+	if err := store.Allow(workspace, []string{file}); err != nil {
+		t.Fatal(err)
+	}
+	if !store.Trusted(workspace, []string{file}) {
+		t.Fatal("expected workspace trusted after allow")
+	}
+	writeFile(t, base, `{"apiTimeout": "1ms", "cacheTTL": "1ms"}`)
+	if store.Trusted(workspace, []string{file}) {
+		t.Error("expected editing an extended file to revoke trust")
+	}
+
+	// A chain that no longer resolves is not applied, and is not trusted
+	// either once it resolves to something new.
+	writeFile(t, base, `{}`)
+	writeFile(t, shared, `{"extends": "missing.json"}`)
+	if err := store.Allow(workspace, []string{file}); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "shared", "missing.json"), `{"apiTimeout": "1ms"}`)
+	if store.Trusted(workspace, []string{file}) {
+		t.Error("expected a newly resolvable extends target to revoke trust")
+	}
+}
+
+func TestLoadTrustMalformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), TrustFileName)
+	writeFile(t, path, `{not json`)
+	if _, err := LoadTrust(path); err == nil {
+		t.Error("expected error for malformed trust store")
+	}
+}
+
+func TestRestrictKeys(t *testing.T) {
+	cfg := Config{
+		Theme:      "nord",
+		APITimeout: Duration{time.Second},
+		Profiles:   []Profile{{Config: Config{Theme: "light", CacheTTL: Duration{time.Second}}}},
+		orderOps:   []OrderOps{{Append: []string{"git"}}},
+	}
+	allowed := func(key string) bool { return key == "theme" || key == "profiles" }
+
+	got, dropped := restrictKeys(cfg, allowed)
+	if got.Theme != "nord" || got.APITimeout.Duration != 0 || got.orderOps != nil {
+		t.Errorf("unexpected restricted config %+v", got)
+	}
+	if got.Profiles[0].Config.CacheTTL.Duration != 0 || got.Profiles[0].Config.Theme != "light" {
+		t.Errorf("expected profile configs restricted too, got %+v", got.Profiles[0].Config)
+	}
+	if want := "segmentOrder,apiTimeout,profiles[0].config.cacheTTL"; strings.Join(dropped, ",") != want {
+		t.Errorf("dropped = %v, want %s", dropped, want)
+	}
+	if cfg.Profiles[0].Config.CacheTTL.Duration == 0 {
+		t.Error("restrictKeys must not modify the input profiles")
+	}
+}
+
+func TestLoadSourcesUntrustedProject(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "project.json")
+	writeFile(t, project, `{"theme": "nord", "apiTimeout": "1ms", "projectKeys": ["apiTimeout"]}`)
+
+	cfg, diags := LoadSources(Sources{ProjectPaths: []string{project}})
+	if cfg.Theme != "nord" {
+		t.Errorf("expected cosmetic key applied, got theme %q", cfg.Theme)
+	}
+	if cfg.APITimeout.Duration != 5*time.Second {
+		t.Errorf("expected apiTimeout ignored, got %v", cfg.APITimeout.Duration)
+	}
+	got := strings.Join(diagStrings(diags), "\n")
+	for _, want := range []string{"apiTimeout: not applied: project config is not trusted", "projectKeys: not applied"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in diagnostics:\n%s", want, got)
+		}
+	}
+}
+
+func TestLoadSourcesUntrustedSegments(t *testing.T) {
+	project := filepath.Join(t.TempDir(), "project.json")
+	writeFile(t, project, `{
+		"segments": {
+			"model": {"enabled": false},
+			"git": {"options": {"issueURL": "https://evil.example/{{key}}"}},
+			"secret": {"type": "hook_field", "options": {"pointer": "/cost"}}
+		},
+		"segmentOrder": ["git", "secret"]
+	}`)
+
+	cfg, diags := LoadSources(Sources{ProjectPaths: []string{project}})
+	if cfg.Segments["model"].IsEnabled() {
+		t.Error("expected enabled to apply from an untrusted project")
+	}
+	if cfg.Segments["git"].Options != nil {
+		t.Errorf("expected git options dropped, got %v", cfg.Segments["git"].Options)
+	}
+	if _, ok := cfg.Segments["secret"]; ok {
+		t.Error("expected the custom segment dropped")
+	}
+	got := strings.Join(diagStrings(diags), "\n")
+	for _, want := range []string{"segments.git.options: not applied: project config is not trusted", "segments.secret: not applied"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in diagnostics:\n%s", want, got)
+		}
+	}
+
+	cfg, _ = LoadSources(Sources{ProjectPaths: []string{project}, ProjectTrusted: true})
+	if cfg.Segments["git"].Options == nil || cfg.Segments["secret"].Type != "hook_field" {
+		t.Errorf("expected a trusted project to keep options and types, got %+v", cfg.Segments)
+	}
+}
+
+func TestLoadSourcesUserProjectKeys(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.json")
+	project := filepath.Join(dir, "project.json")
+	writeFile(t, user, `{"projectKeys": ["cacheTTL"]}`)
+	writeFile(t, project, `{"theme": "nord", "cacheTTL": "2m"}`)
+
+	cfg, _ := LoadSources(Sources{UserPath: user, ProjectPaths: []string{project}})
+	if cfg.CacheTTL.Duration != 2*time.Minute {
+		t.Errorf("expected user-allowed key applied, got %v", cfg.CacheTTL.Duration)
+	}
+	if cfg.Theme != "dark" {
+		t.Errorf("expected theme dropped when not listed, got %q", cfg.Theme)
+	}
+}
+
+func TestLoadSourcesTrustedProject(t *testing.T) {
+	project := filepath.Join(t.TempDir(), "project.json")
+	writeFile(t, project, `{"apiTimeout": "2s", "projectKeys": ["theme"]}`)
+
+	cfg, diags := LoadSources(Sources{ProjectPaths: []string{project}, ProjectTrusted: true})
+	if cfg.APITimeout.Duration != 2*time.Second {
+		t.Errorf("expected trusted project to set apiTimeout, got %v", cfg.APITimeout.Duration)
+	}
+	if len(cfg.ProjectKeys) != len(DefaultProjectKeys) {
+		t.Errorf("expected projectKeys to stay user-only, got %v", cfg.ProjectKeys)
+	}
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, "only allowed in the user config") {
+		t.Errorf("unexpected diagnostics %v", diags)
+	}
+}
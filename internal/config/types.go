@@ -4,20 +4,31 @@ package config
 import "time"
 
 // Config is the top-level configuration structure.
+// Field descriptions in `desc` tags feed the generated JSON Schema.
 type Config struct {
-	Display        DisplayConfig            `json:"display"`
-	Segments       map[string]SegmentConfig `json:"segments"`
-	Theme          string                   `json:"theme"`
-	SegmentOrder   []string                 `json:"segmentOrder"`
-	APITimeout     Duration                 `json:"apiTimeout"`
-	CacheTTL       Duration                 `json:"cacheTTL"`
-	TrendThreshold float64                  `json:"trendThreshold"`
+	Schema         string                     `json:"$schema,omitempty" desc:"Path or URL of the JSON Schema, for editor validation."`
+	Extends        string                     `json:"extends,omitempty" desc:"Path of a config file to inherit from, relative to this file."`
+	Display        DisplayConfig              `json:"display" desc:"Rendering options."`
+	Segments       map[string]SegmentConfig   `json:"segments" desc:"Per-segment settings, keyed by segment name."`
+	Theme          string                     `json:"theme" desc:"Color theme name."`
+	SegmentOrder   []string                   `json:"segmentOrder" desc:"Order of segments left-to-right, or an object of edits to the inherited order."`
+	APITimeout     Duration                   `json:"apiTimeout" desc:"HTTP timeout for the usage API and workflow CLI."`
+	CacheTTL       Duration                   `json:"cacheTTL" desc:"Cache lifetime for usage API responses."`
+	GitTimeout     Duration                   `json:"gitTimeout" desc:"Timeout for git commands run by the git and diff segments."`
+	TrendThreshold float64                    `json:"trendThreshold" desc:"Percentage change threshold for trend arrows."`
+	Thresholds     map[string]ThresholdConfig `json:"thresholds" desc:"Color thresholds for block, weekly, opus, sonnet, context, session and diff."`
+	Profiles       []Profile                  `json:"profiles,omitempty" desc:"Conditional overrides applied after all config files when their matchers hold."`
+	ProjectKeys    []string                   `json:"projectKeys,omitempty" desc:"Top-level keys that project configs may set before the workspace is trusted with 'conductor-powerline allow'. User config only."`
+
+	// orderOps holds segmentOrder edits not yet applied because no layer
+	// below has provided a full order (see MergeConfig).
+	orderOps []OrderOps
 }
 
 // DisplayConfig controls rendering behavior.
 type DisplayConfig struct {
-	NerdFonts    *bool `json:"nerdFonts,omitempty"`
-	CompactWidth int   `json:"compactWidth"`
+	NerdFonts    *bool `json:"nerdFonts,omitempty" desc:"Use Nerd Font glyphs."`
+	CompactWidth int   `json:"compactWidth" desc:"Truncate segments when total width exceeds this."`
 }
 
 // NerdFontsEnabled returns the effective NerdFonts value, defaulting to true if nil.
@@ -33,9 +44,51 @@ func boolPtr(b bool) *bool {
 	return &b
 }
 
-// SegmentConfig controls an individual segment's behavior.
+// SegmentConfig controls an individual segment's behavior. Fields are
+// pointers so layers only override what they set.
 type SegmentConfig struct {
-	Enabled bool `json:"enabled"`
+	Type    string         `json:"type,omitempty" desc:"Makes this a custom segment of the given type, e.g. hook_field. Only for names that are not built-in segments."`
+	Enabled *bool          `json:"enabled,omitempty" desc:"Show this segment."`
+	Options map[string]any `json:"options,omitempty" desc:"Segment-specific settings; see the README for each segment's keys."`
+}
+
+// IsEnabled returns the effective Enabled value, defaulting to true if nil.
+func (s SegmentConfig) IsEnabled() bool {
+	if s.Enabled == nil {
+		return true
+	}
+	return *s.Enabled
+}
+
+// ThresholdConfig sets the usage percentages at which a segment switches to
+// the warning and critical palettes. When Levels is set it replaces Warning and
+// Critical, allowing any number of steps mapped to theme color keys.
+type ThresholdConfig struct {
+	Warning  float64          `json:"warning,omitempty" desc:"Percentage at which the warning colors apply."`
+	Critical float64          `json:"critical,omitempty" desc:"Percentage at which the critical colors apply."`
+	Levels   []ThresholdLevel `json:"levels,omitempty" desc:"Custom ascending scale; replaces warning and critical."`
+}
+
+// ThresholdLevel is one step of a custom threshold scale.
+type ThresholdLevel struct {
+	At    float64 `json:"at" desc:"Percentage at which this level starts."`
+	Color string  `json:"color" desc:"Theme color key to use, e.g. warning or critical."`
+}
+
+// ResolvedLevels returns the effective levels for this threshold: Levels when
+// set, otherwise the warning and critical steps that are non-zero.
+func (t ThresholdConfig) ResolvedLevels() []ThresholdLevel {
+	if len(t.Levels) > 0 {
+		return t.Levels
+	}
+	var levels []ThresholdLevel
+	if t.Warning != 0 {
+		levels = append(levels, ThresholdLevel{At: t.Warning, Color: "warning"})
+	}
+	if t.Critical != 0 {
+		levels = append(levels, ThresholdLevel{At: t.Critical, Color: "critical"})
+	}
+	return levels
 }
 
 // Duration wraps time.Duration for JSON marshaling as a string (e.g., "5s", "30s").
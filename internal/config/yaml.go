@@ -0,0 +1,434 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseYAML decodes the subset of YAML needed for config files into plain Go
+// values (map[string]any, []any, string, float64, int64, bool, nil).
+//
+// Supported: block mappings and sequences, flow collections on a single line
+// ([a, b], {k: v}), single/double-quoted and plain scalars, and # comments.
+// Anchors, tags, multi-document streams and block scalars (| and >) are not
+// supported and produce an error rather than a silent misparse.
+func parseYAML(data []byte) (any, error) {
+	lines, err := yamlLines(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return map[string]any{}, nil
+	}
+	p := &yamlParser{lines: lines}
+	v, err := p.parseBlock(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		return nil, fmt.Errorf("yaml: line %d: unexpected indentation", l.num)
+	}
+	return v, nil
+}
+
+// yamlLine is a non-empty, comment-stripped source line.
+type yamlLine struct {
+	num    int // 1-based source line number, for error messages
+	indent int
+	text   string
+}
+
+// yamlLines splits src into meaningful lines, dropping blanks, comments and
+// document markers.
+func yamlLines(src string) ([]yamlLine, error) {
+	var out []yamlLine
+	for i, raw := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		text := stripYAMLComment(raw)
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || trimmed == "---" || trimmed == "..." {
+			continue
+		}
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+		if strings.HasPrefix(text[indent:], "\t") {
+			return nil, fmt.Errorf("yaml: line %d: tabs are not allowed for indentation", i+1)
+		}
+		out = append(out, yamlLine{num: i + 1, indent: indent, text: strings.TrimRight(text[indent:], " \t")})
+	}
+	return out, nil
+}
+
+// stripYAMLComment removes a trailing # comment that is not inside quotes.
+// A # only starts a comment at line start or after whitespace.
+func stripYAMLComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// parseBlock parses the mapping or sequence whose entries start at indent.
+func (p *yamlParser) parseBlock(indent int) (any, error) {
+	if isYAMLSeqItem(p.lines[p.pos].text) {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) parseSequence(indent int) ([]any, error) {
+	seq := []any{}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent != indent || !isYAMLSeqItem(l.text) {
+			break
+		}
+		rest := strings.TrimLeft(strings.TrimPrefix(l.text, "-"), " ")
+		if rest == "" {
+			p.pos++
+			v, err := p.parseNested(indent)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+			continue
+		}
+		if _, _, ok := splitYAMLKey(rest); ok || isYAMLSeqItem(rest) {
+			// "- key: value" starts a mapping (or nested sequence) whose
+			// entries are aligned with the text after the dash.
+			p.lines[p.pos] = yamlLine{num: l.num, indent: indent + len(l.text) - len(rest), text: rest}
+			v, err := p.parseBlock(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+			continue
+		}
+		v, err := parseYAMLInline(rest, l.num)
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, v)
+		p.pos++
+	}
+	return seq, nil
+}
+
+func (p *yamlParser) parseMapping(indent int) (map[string]any, error) {
+	m := map[string]any{}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("yaml: line %d: unexpected indentation", l.num)
+		}
+		if isYAMLSeqItem(l.text) {
+			break
+		}
+		key, value, ok := splitYAMLKey(l.text)
+		if !ok {
+			return nil, fmt.Errorf("yaml: line %d: expected \"key: value\"", l.num)
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("yaml: line %d: duplicate key %q", l.num, key)
+		}
+		p.pos++
+		if value == "" {
+			v, err := p.parseNested(indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+			continue
+		}
+		v, err := parseYAMLInline(value, l.num)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// parseNested parses the value of a key or dash with nothing after it: a
+// deeper-indented block, a sequence at the same indent (allowed under a
+// mapping key), or null when neither follows.
+func (p *yamlParser) parseNested(parentIndent int) (any, error) {
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.pos]
+	if next.indent > parentIndent {
+		return p.parseBlock(next.indent)
+	}
+	if next.indent == parentIndent && isYAMLSeqItem(next.text) && !isYAMLSeqItem(p.lines[p.pos-1].text) {
+		return p.parseSequence(next.indent)
+	}
+	return nil, nil
+}
+
+// splitYAMLKey splits "key: value" at the first unquoted ": " (or a trailing
+// ":"). Quoted keys are unquoted.
+func splitYAMLKey(text string) (key, value string, ok bool) {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case i == 0 && (c == '"' || c == '\''):
+			quote = c
+		case c == '[' || c == '{':
+			if i == 0 {
+				return "", "", false
+			}
+		case c == ':' && (i == len(text)-1 || text[i+1] == ' '):
+			key = strings.TrimSpace(text[:i])
+			if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') {
+				s, err := parseYAMLQuoted(key)
+				if err != nil {
+					return "", "", false
+				}
+				key = s
+			}
+			return key, strings.TrimSpace(text[i+1:]), key != ""
+		}
+	}
+	return "", "", false
+}
+
+// parseYAMLInline parses a scalar or single-line flow collection.
+func parseYAMLInline(s string, num int) (any, error) {
+	if s == "|" || s == ">" || strings.HasPrefix(s, "|") || strings.HasPrefix(s, ">") {
+		return nil, fmt.Errorf("yaml: line %d: block scalars are not supported", num)
+	}
+	if strings.HasPrefix(s, "&") || strings.HasPrefix(s, "*") || strings.HasPrefix(s, "!") {
+		return nil, fmt.Errorf("yaml: line %d: anchors, aliases and tags are not supported", num)
+	}
+	f := &yamlFlow{s: s, num: num}
+	v, err := f.value()
+	if err != nil {
+		return nil, err
+	}
+	f.skipSpace()
+	if f.pos != len(f.s) {
+		return nil, fmt.Errorf("yaml: line %d: unexpected %q", num, f.s[f.pos:])
+	}
+	return v, nil
+}
+
+// yamlFlow is a cursor over a single-line flow value.
+type yamlFlow struct {
+	s     string
+	pos   int
+	num   int
+	depth int // nesting level of flow collections at pos
+}
+
+func (f *yamlFlow) skipSpace() {
+	for f.pos < len(f.s) && (f.s[f.pos] == ' ' || f.s[f.pos] == '\t') {
+		f.pos++
+	}
+}
+
+func (f *yamlFlow) value() (any, error) {
+	f.skipSpace()
+	if f.pos >= len(f.s) {
+		return nil, nil
+	}
+	switch f.s[f.pos] {
+	case '[':
+		return f.sequence()
+	case '{':
+		return f.mapping()
+	case '"', '\'':
+		return f.quoted()
+	}
+	return f.plain(), nil
+}
+
+func (f *yamlFlow) sequence() ([]any, error) {
+	f.pos++ // [
+	f.depth++
+	defer func() { f.depth-- }()
+	seq := []any{}
+	for {
+		f.skipSpace()
+		if f.pos >= len(f.s) {
+			return nil, fmt.Errorf("yaml: line %d: unterminated flow sequence", f.num)
+		}
+		if f.s[f.pos] == ']' {
+			f.pos++
+			return seq, nil
+		}
+		v, err := f.value()
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, v)
+		if err := f.separator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (f *yamlFlow) mapping() (map[string]any, error) {
+	f.pos++ // {
+	f.depth++
+	defer func() { f.depth-- }()
+	m := map[string]any{}
+	for {
+		f.skipSpace()
+		if f.pos >= len(f.s) {
+			return nil, fmt.Errorf("yaml: line %d: unterminated flow mapping", f.num)
+		}
+		if f.s[f.pos] == '}' {
+			f.pos++
+			return m, nil
+		}
+		var key string
+		if c := f.s[f.pos]; c == '"' || c == '\'' {
+			k, err := f.quoted()
+			if err != nil {
+				return nil, err
+			}
+			key = k
+		} else {
+			start := f.pos
+			for f.pos < len(f.s) && f.s[f.pos] != ':' && f.s[f.pos] != ',' && f.s[f.pos] != '}' {
+				f.pos++
+			}
+			key = strings.TrimSpace(f.s[start:f.pos])
+		}
+		f.skipSpace()
+		if f.pos >= len(f.s) || f.s[f.pos] != ':' {
+			return nil, fmt.Errorf("yaml: line %d: expected ':' after key %q", f.num, key)
+		}
+		f.pos++
+		v, err := f.value()
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+		if err := f.separator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// separator consumes a ',' or leaves the closing bracket for the caller.
+func (f *yamlFlow) separator(closing byte) error {
+	f.skipSpace()
+	if f.pos < len(f.s) && f.s[f.pos] == ',' {
+		f.pos++
+		return nil
+	}
+	if f.pos < len(f.s) && f.s[f.pos] == closing {
+		return nil
+	}
+	return fmt.Errorf("yaml: line %d: expected ',' or '%c'", f.num, closing)
+}
+
+func (f *yamlFlow) quoted() (string, error) {
+	q := f.s[f.pos]
+	end := f.pos + 1
+	for end < len(f.s) {
+		if f.s[end] == '\\' && q == '"' {
+			end += 2
+			continue
+		}
+		if f.s[end] == q {
+			if q == '\'' && end+1 < len(f.s) && f.s[end+1] == '\'' {
+				end += 2
+				continue
+			}
+			break
+		}
+		end++
+	}
+	if end >= len(f.s) {
+		return "", fmt.Errorf("yaml: line %d: unterminated string", f.num)
+	}
+	s, err := parseYAMLQuoted(f.s[f.pos : end+1])
+	if err != nil {
+		return "", fmt.Errorf("yaml: line %d: %v", f.num, err)
+	}
+	f.pos = end + 1
+	return s, nil
+}
+
+// plain reads an unquoted scalar. Inside flow collections it stops at the
+// next ',', ']' or '}'.
+func (f *yamlFlow) plain() any {
+	start := f.pos
+	for f.pos < len(f.s) {
+		c := f.s[f.pos]
+		if f.depth > 0 && (c == ',' || c == ']' || c == '}') {
+			break
+		}
+		f.pos++
+	}
+	return yamlScalar(strings.TrimSpace(f.s[start:f.pos]))
+}
+
+// parseYAMLQuoted unquotes a single- or double-quoted YAML string.
+func parseYAMLQuoted(s string) (string, error) {
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	// YAML double-quoted escapes are a superset of JSON's for the cases
+	// config files use (\n, \t, \", \\, \uXXXX).
+	var out string
+	if err := json.Unmarshal([]byte(s), &out); err != nil {
+		return "", fmt.Errorf("invalid double-quoted string %s", s)
+	}
+	return out, nil
+}
+
+// yamlScalar resolves a plain scalar using the YAML 1.2 core schema.
+func yamlScalar(s string) any {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !strings.ContainsAny(s, "xXpP_") {
+		return f
+	}
+	return s
+}
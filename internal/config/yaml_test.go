@@ -0,0 +1,122 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAMLMappingsAndSequences(t *testing.T) {
+	src := `
+# user config
+theme: nord
+display:
+  nerdFonts: false   # plain separators
+  compactWidth: 80
+segmentOrder:
+  - model
+  - "block"
+segments:
+  git: { enabled: false }
+apiTimeout: 10s
+trendThreshold: 2.5
+`
+	got, err := parseYAML([]byte(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]any{
+		"theme": "nord",
+		"display": map[string]any{
+			"nerdFonts":    false,
+			"compactWidth": int64(80),
+		},
+		"segmentOrder": []any{"model", "block"},
+		"segments": map[string]any{
+			"git": map[string]any{"enabled": false},
+		},
+		"apiTimeout":     "10s",
+		"trendThreshold": 2.5,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseYAML mismatch\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestParseYAMLSequenceOfMappings(t *testing.T) {
+	src := `levels:
+- at: 40
+  color: weekly
+- at: 85
+  color: critical
+`
+	got, err := parseYAML([]byte(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]any{
+		"levels": []any{
+			map[string]any{"at": int64(40), "color": "weekly"},
+			map[string]any{"at": int64(85), "color": "critical"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseYAML mismatch\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestParseYAMLScalars(t *testing.T) {
+	tests := []struct {
+		in   string
+		want any
+	}{
+		{`v: "a # not a comment"`, "a # not a comment"},
+		{`v: 'it''s'`, "it's"},
+		{`v: ~`, nil},
+		{`v: true`, true},
+		{`v: 1.5`, 1.5},
+		{`v: https://example.com/x`, "https://example.com/x"},
+		{`v: [a, 'b, c', 3]`, []any{"a", "b, c", int64(3)}},
+		{`"quoted key": yes`, "yes"},
+	}
+	for _, tt := range tests {
+		got, err := parseYAML([]byte(tt.in))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.in, err)
+			continue
+		}
+		var v any
+		for _, val := range got.(map[string]any) {
+			v = val
+		}
+		if !reflect.DeepEqual(v, tt.want) {
+			t.Errorf("%s: got %#v, want %#v", tt.in, v, tt.want)
+		}
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []string{
+		"a: 1\n  b: 2",
+		"a: 1\na: 2",
+		"text: |\n  block",
+		"a: &anchor 1",
+		"a: [1, 2",
+		"\ta: 1",
+		"just a string without colon",
+	}
+	for _, src := range tests {
+		if _, err := parseYAML([]byte(src)); err == nil {
+			t.Errorf("expected error for %q", src)
+		}
+	}
+}
+
+func TestParseYAMLEmpty(t *testing.T) {
+	got, err := parseYAML([]byte("# only a comment\n---\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m, ok := got.(map[string]any); !ok || len(m) != 0 {
+		t.Errorf("expected empty mapping, got %#v", got)
+	}
+}
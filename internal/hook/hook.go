@@ -22,14 +22,33 @@ type ContextWindow struct {
 	RemainingPercentage *float64           `json:"remaining_percentage"`
 }
 
+// Cost holds the session totals Claude Code reports in the cost field.
+type Cost struct {
+	TotalCostUSD       float64 `json:"total_cost_usd"`
+	TotalDurationMS    int64   `json:"total_duration_ms"`
+	TotalAPIDurationMS int64   `json:"total_api_duration_ms"`
+	TotalLinesAdded    int     `json:"total_lines_added"`
+	TotalLinesRemoved  int     `json:"total_lines_removed"`
+}
+
 // Data holds the parsed hook input from Claude Code.
 // Supports both legacy string format and Claude Code's object format
-// for model and workspace fields. Fields are resolved once during unmarshal.
+// for model, workspace and output_style fields. Fields are resolved once
+// during unmarshal; a field of an unexpected type is treated as absent
+// rather than failing the whole payload.
 type Data struct {
-	modelID          string
-	modelDisplayName string
-	workspacePath    string
-	contextWindow    *ContextWindow
+	modelID           string
+	modelDisplayName  string
+	workspacePath     string
+	currentDir        string
+	contextWindow     *ContextWindow
+	sessionID         string
+	transcriptPath    string
+	version           string
+	outputStyle       string
+	cost              *Cost
+	exceeds200kTokens bool
+	raw               json.RawMessage
 
 	Context json.RawMessage `json:"context"`
 }
@@ -50,10 +69,17 @@ type workspaceObject struct {
 // forms for model and workspace fields. Values are resolved eagerly.
 func (d *Data) UnmarshalJSON(data []byte) error {
 	type alias struct {
-		Model         json.RawMessage `json:"model"`
-		Workspace     json.RawMessage `json:"workspace"`
-		Context       json.RawMessage `json:"context"`
-		ContextWindow json.RawMessage `json:"context_window"`
+		Model             json.RawMessage `json:"model"`
+		Workspace         json.RawMessage `json:"workspace"`
+		Cwd               json.RawMessage `json:"cwd"`
+		Context           json.RawMessage `json:"context"`
+		ContextWindow     json.RawMessage `json:"context_window"`
+		SessionID         json.RawMessage `json:"session_id"`
+		TranscriptPath    json.RawMessage `json:"transcript_path"`
+		Version           json.RawMessage `json:"version"`
+		OutputStyle       json.RawMessage `json:"output_style"`
+		Cost              json.RawMessage `json:"cost"`
+		Exceeds200kTokens json.RawMessage `json:"exceeds_200k_tokens"`
 	}
 
 	var a alias
@@ -61,13 +87,77 @@ func (d *Data) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	d.raw = append(json.RawMessage(nil), data...)
 	d.Context = a.Context
 	d.modelID, d.modelDisplayName = resolveModel(a.Model)
 	d.workspacePath = resolveWorkspace(a.Workspace)
+	d.currentDir = resolveCurrentDir(a.Workspace, a.Cwd)
 	d.contextWindow = resolveContextWindow(a.ContextWindow)
+	d.sessionID = resolveString(a.SessionID)
+	d.transcriptPath = resolveString(a.TranscriptPath)
+	d.version = resolveString(a.Version)
+	d.outputStyle = resolveOutputStyle(a.OutputStyle)
+	d.cost = resolveCost(a.Cost)
+	d.exceeds200kTokens = resolveBool(a.Exceeds200kTokens)
 	return nil
 }
 
+// resolveString returns raw as a string, or "" when absent or not a string.
+func resolveString(raw json.RawMessage) string {
+	var s string
+	if len(raw) == 0 || json.Unmarshal(raw, &s) != nil {
+		return ""
+	}
+	return s
+}
+
+// resolveBool returns raw as a bool, or false when absent or not a bool.
+func resolveBool(raw json.RawMessage) bool {
+	var b bool
+	if len(raw) == 0 || json.Unmarshal(raw, &b) != nil {
+		return false
+	}
+	return b
+}
+
+// resolveCurrentDir prefers workspace.current_dir, then the top-level cwd,
+// then the project directory, so it is only empty when both are.
+func resolveCurrentDir(workspace, cwd json.RawMessage) string {
+	var obj workspaceObject
+	if len(workspace) > 0 && json.Unmarshal(workspace, &obj) == nil && obj.CurrentDir != "" {
+		return obj.CurrentDir
+	}
+	if dir := resolveString(cwd); dir != "" {
+		return dir
+	}
+	return resolveWorkspace(workspace)
+}
+
+// resolveOutputStyle accepts both "name" and {"name": "..."}.
+func resolveOutputStyle(raw json.RawMessage) string {
+	if s := resolveString(raw); s != "" {
+		return s
+	}
+	var obj struct {
+		Name string `json:"name"`
+	}
+	if len(raw) == 0 || json.Unmarshal(raw, &obj) != nil {
+		return ""
+	}
+	return obj.Name
+}
+
+func resolveCost(raw json.RawMessage) *Cost {
+	if len(raw) == 0 {
+		return nil
+	}
+	var c Cost
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil
+	}
+	return &c
+}
+
 func resolveModel(raw json.RawMessage) (id, displayName string) {
 	if len(raw) == 0 {
 		return "", ""
@@ -153,6 +243,50 @@ func (d Data) WorkspacePath() string {
 	return d.workspacePath
 }
 
+// CurrentDir returns the directory Claude Code is working in, which may be
+// below WorkspacePath. Falls back to the top-level cwd field, then to
+// WorkspacePath.
+func (d Data) CurrentDir() string {
+	return d.currentDir
+}
+
+// SessionID returns the Claude Code session identifier.
+func (d Data) SessionID() string {
+	return d.sessionID
+}
+
+// TranscriptPath returns the path of the session's JSONL transcript.
+func (d Data) TranscriptPath() string {
+	return d.transcriptPath
+}
+
+// Version returns the Claude Code version that sent the payload.
+func (d Data) Version() string {
+	return d.version
+}
+
+// OutputStyle returns the active output style name, e.g. "default".
+func (d Data) OutputStyle() string {
+	return d.outputStyle
+}
+
+// Cost returns the session cost totals, or nil if absent.
+func (d Data) Cost() *Cost {
+	return d.cost
+}
+
+// Exceeds200kTokens reports whether the session's context has exceeded
+// 200k tokens.
+func (d Data) Exceeds200kTokens() bool {
+	return d.exceeds200kTokens
+}
+
+// Raw returns the payload exactly as Claude Code sent it, or nil if it was
+// empty.
+func (d Data) Raw() json.RawMessage {
+	return d.raw
+}
+
 // Parse reads JSON from r and returns the parsed hook data.
 // Returns a zero-value Data for empty input. Returns an error for malformed JSON.
 func Parse(r io.Reader) (Data, error) {
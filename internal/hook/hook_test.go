@@ -492,3 +492,132 @@ func TestParseWorkspaceFallbackToCurrentDir(t *testing.T) {
 		t.Errorf("WorkspacePath() = %q, want %q", data.WorkspacePath(), "/Users/dev/project/sub")
 	}
 }
+
+func TestParseSessionFields(t *testing.T) {
+	input := `{
+		"session_id": "abc-123",
+		"transcript_path": "/home/dev/.claude/projects/p/abc-123.jsonl",
+		"cwd": "/home/dev/project",
+		"model": {"id": "claude-opus-4-6", "display_name": "Opus"},
+		"workspace": {"current_dir": "/home/dev/project/src", "project_dir": "/home/dev/project"},
+		"version": "1.0.80",
+		"output_style": {"name": "explanatory"},
+		"cost": {
+			"total_cost_usd": 0.0123,
+			"total_duration_ms": 45000,
+			"total_api_duration_ms": 2300,
+			"total_lines_added": 156,
+			"total_lines_removed": 23
+		},
+		"exceeds_200k_tokens": true
+	}`
+
+	data, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.SessionID() != "abc-123" {
+		t.Errorf("SessionID() = %q", data.SessionID())
+	}
+	if data.TranscriptPath() != "/home/dev/.claude/projects/p/abc-123.jsonl" {
+		t.Errorf("TranscriptPath() = %q", data.TranscriptPath())
+	}
+	if data.Version() != "1.0.80" {
+		t.Errorf("Version() = %q", data.Version())
+	}
+	if data.OutputStyle() != "explanatory" {
+		t.Errorf("OutputStyle() = %q", data.OutputStyle())
+	}
+	if data.CurrentDir() != "/home/dev/project/src" {
+		t.Errorf("CurrentDir() = %q", data.CurrentDir())
+	}
+	if !data.Exceeds200kTokens() {
+		t.Error("expected Exceeds200kTokens() true")
+	}
+
+	cost := data.Cost()
+	if cost == nil {
+		t.Fatal("expected cost data")
+	}
+	want := Cost{TotalCostUSD: 0.0123, TotalDurationMS: 45000, TotalAPIDurationMS: 2300, TotalLinesAdded: 156, TotalLinesRemoved: 23}
+	if *cost != want {
+		t.Errorf("Cost() = %+v, want %+v", *cost, want)
+	}
+}
+
+func TestParseSessionFieldsAbsent(t *testing.T) {
+	data, err := Parse(strings.NewReader(`{"model":"claude-opus-4-6"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.SessionID() != "" || data.TranscriptPath() != "" || data.Version() != "" || data.OutputStyle() != "" {
+		t.Error("expected empty string accessors when fields are absent")
+	}
+	if data.Cost() != nil {
+		t.Error("expected nil cost when absent")
+	}
+	if data.Exceeds200kTokens() {
+		t.Error("expected Exceeds200kTokens() false when absent")
+	}
+}
+
+func TestParseSessionFieldsWrongTypes(t *testing.T) {
+	input := `{
+		"model": "claude-opus-4-6",
+		"session_id": 42,
+		"cost": "expensive",
+		"exceeds_200k_tokens": "yes",
+		"output_style": "concise"
+	}`
+
+	data, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("wrong-typed fields must not fail the payload: %v", err)
+	}
+	if data.ModelID() != "claude-opus-4-6" {
+		t.Errorf("expected other fields still parsed, got model %q", data.ModelID())
+	}
+	if data.SessionID() != "" || data.Cost() != nil || data.Exceeds200kTokens() {
+		t.Error("expected wrong-typed fields treated as absent")
+	}
+	if data.OutputStyle() != "concise" {
+		t.Errorf("expected string output_style accepted, got %q", data.OutputStyle())
+	}
+}
+
+func TestCurrentDirFallbacks(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"workspace": {"project_dir": "/p"}, "cwd": "/p/sub"}`, "/p/sub"},
+		{`{"workspace": "/legacy"}`, "/legacy"},
+		{`{"workspace": {"project_dir": "/p"}}`, "/p"},
+		{`{}`, ""},
+	}
+	for _, tt := range tests {
+		data, err := Parse(strings.NewReader(tt.input))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := data.CurrentDir(); got != tt.want {
+			t.Errorf("CurrentDir() for %s = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRawKeepsPayload(t *testing.T) {
+	input := `{"model": "claude-opus-4-6", "unknown_field": {"a": 1}}`
+	data, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data.Raw()) != input {
+		t.Errorf("Raw() = %s, want the original payload", data.Raw())
+	}
+
+	empty, _ := Parse(strings.NewReader(""))
+	if empty.Raw() != nil {
+		t.Errorf("expected nil Raw() for empty input, got %s", empty.Raw())
+	}
+}
@@ -0,0 +1,53 @@
+package hook
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrInteractive is returned by ReadStdin when stdin is a terminal, i.e. the
+// binary was run by hand or from a shell prompt with nothing piped in.
+var ErrInteractive = errors.New("stdin is a terminal")
+
+// isTerminal reports whether f is a character device such as a TTY.
+// It is a package-level variable to allow testing with mocks.
+var isTerminal = func(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ReadStdin reads hook JSON from f, normally os.Stdin. It returns
+// ErrInteractive without reading when f is a terminal, and gives up after
+// timeout so a parent that never closes the pipe cannot hang the statusline.
+func ReadStdin(f *os.File, timeout time.Duration) ([]byte, error) {
+	if isTerminal(f) {
+		return nil, ErrInteractive
+	}
+	return readWithTimeout(f, timeout)
+}
+
+// readWithTimeout reads r to EOF, or fails once timeout elapses. The reading
+// goroutine is abandoned on timeout; the process exits soon after.
+func readWithTimeout(r io.Reader, timeout time.Duration) ([]byte, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		data, err := io.ReadAll(r)
+		done <- result{data, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		return res.data, res.err
+	case <-timer.C:
+		return nil, fmt.Errorf("no hook data on stdin after %v", timeout)
+	}
+}
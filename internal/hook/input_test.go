@@ -0,0 +1,54 @@
+package hook
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadStdinSkipsTerminal(t *testing.T) {
+	orig := isTerminal
+	defer func() { isTerminal = orig }()
+	isTerminal = func(*os.File) bool { return true }
+
+	if _, err := ReadStdin(os.Stdin, time.Second); !errors.Is(err, ErrInteractive) {
+		t.Errorf("expected ErrInteractive, got %v", err)
+	}
+}
+
+func TestReadStdinPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	go func() {
+		_, _ = w.WriteString(`{"model":"claude-opus-4-6"}`)
+		_ = w.Close()
+	}()
+
+	data, err := ReadStdin(r, time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != `{"model":"claude-opus-4-6"}` {
+		t.Errorf("unexpected data %q", data)
+	}
+}
+
+func TestReadWithTimeoutGivesUp(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+
+	start := time.Now()
+	_, err := readWithTimeout(r, 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "no hook data") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("expected read to give up promptly")
+	}
+}
@@ -0,0 +1,49 @@
+package hook
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Field returns the value at the JSON pointer (RFC 6901), e.g.
+// "/output_style/name" or "/workspace/added_dirs/0", in the raw payload.
+// Values have the types encoding/json decodes into any. The second result
+// is false when the payload has no such value.
+func (d Data) Field(pointer string) (any, bool) {
+	if len(d.raw) == 0 {
+		return nil, false
+	}
+	var v any
+	if err := json.Unmarshal(d.raw, &v); err != nil {
+		return nil, false
+	}
+	if pointer == "" {
+		return v, true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = unescape.Replace(token)
+		switch node := v.(type) {
+		case map[string]any:
+			next, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
@@ -0,0 +1,50 @@
+package hook
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestField(t *testing.T) {
+	input := `{
+		"version": "2.1.0",
+		"output_style": {"name": "Explanatory"},
+		"workspace": {"added_dirs": ["/a", "/b"]},
+		"cost": {"total_cost_usd": 0.42},
+		"odd/key": {"~tilde": true}
+	}`
+	data, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		pointer string
+		want    any
+	}{
+		{"/version", "2.1.0"},
+		{"/output_style/name", "Explanatory"},
+		{"/workspace/added_dirs/1", "/b"},
+		{"/cost/total_cost_usd", 0.42},
+		{"/odd~1key/~0tilde", true},
+	}
+	for _, tt := range tests {
+		got, ok := data.Field(tt.pointer)
+		if !ok || got != tt.want {
+			t.Errorf("Field(%q) = %v, %v; want %v", tt.pointer, got, ok, tt.want)
+		}
+	}
+
+	for _, pointer := range []string{"/missing", "/version/x", "/workspace/added_dirs/2", "/workspace/added_dirs/x", "version"} {
+		if v, ok := data.Field(pointer); ok {
+			t.Errorf("Field(%q) = %v, expected not found", pointer, v)
+		}
+	}
+}
+
+func TestFieldEmptyPayload(t *testing.T) {
+	var data Data
+	if _, ok := data.Field("/version"); ok {
+		t.Error("expected no fields without a payload")
+	}
+}
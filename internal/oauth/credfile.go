@@ -5,6 +5,8 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+
+	"github.com/rbarcante/conductor-powerline/internal/claudehome"
 )
 
 // credfilePathResolver returns the path to the credentials file.
@@ -15,7 +17,8 @@ type claudeAiOAuthEntry struct {
 	AccessToken string `json:"accessToken"`
 }
 
-// credentialFile represents the JSON structure of ~/.claude/.credentials.json.
+// credentialFile represents the JSON structure of ~/.claude/.credentials.json
+// ($CLAUDE_CONFIG_DIR/.credentials.json when set).
 // Supports both Claude Code's format {"claudeAiOauth":{"accessToken":"..."}}
 // and the legacy flat format {"oauthToken":"..."}.
 type credentialFile struct {
@@ -51,9 +54,9 @@ func getCredfileToken() (string, error) {
 }
 
 func defaultCredfilePath() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
+	dir := claudehome.Dir()
+	if dir == "" {
 		return ""
 	}
-	return filepath.Join(home, ".claude", ".credentials.json")
+	return filepath.Join(dir, ".credentials.json")
 }
@@ -117,3 +117,19 @@ func TestCredfileEmptyToken(t *testing.T) {
 		t.Error("expected error for empty token in credentials file")
 	}
 }
+
+func TestDefaultCredfilePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	if got, want := defaultCredfilePath(), filepath.Join(home, ".claude", ".credentials.json"); got != want {
+		t.Errorf("defaultCredfilePath() = %q, want %q", got, want)
+	}
+
+	custom := filepath.Join(t.TempDir(), "claude-work")
+	t.Setenv("CLAUDE_CONFIG_DIR", custom)
+	if got, want := defaultCredfilePath(), filepath.Join(custom, ".credentials.json"); got != want {
+		t.Errorf("defaultCredfilePath() = %q, want %q", got, want)
+	}
+}
@@ -50,6 +50,29 @@ func osc8CloseStr() string {
 	return "\033[24m\033]8;;\033\\"
 }
 
+// styledText returns the segment text with each span's foreground color
+// applied, restoring the segment FG after it, and span links wrapped in
+// OSC 8 (dropped in tmux, where they would not be clickable). Compact mode
+// truncates the plain Text instead, as escapes cannot be cut safely.
+func styledText(seg segments.Segment) string {
+	if len(seg.Spans) == 0 {
+		return seg.Text
+	}
+	var b strings.Builder
+	for _, span := range seg.Spans {
+		text := span.Text
+		if span.Link != "" && !inTmux {
+			text = osc8Open(span.Link) + text + osc8CloseStr()
+		}
+		if span.FG == "" || span.FG == seg.FG {
+			b.WriteString(text)
+			continue
+		}
+		fmt.Fprintf(&b, "\033[38;5;%sm%s\033[38;5;%sm", span.FG, text, seg.FG)
+	}
+	return b.String()
+}
+
 // Render produces an ANSI-colored powerline string from ordered segments.
 // It skips disabled segments, applies compact mode below the given terminal width,
 // and returns a string with no trailing newline.
@@ -73,7 +96,7 @@ func Render(segs []segments.Segment, nerdFonts bool, termWidth int) string {
 	var b strings.Builder
 
 	for i, seg := range active {
-		text := seg.Text
+		text := styledText(seg)
 		if compact {
 			text = texts[i]
 		}
@@ -138,12 +161,12 @@ func RenderRight(segs []segments.Segment, nerdFonts bool) string {
 				prev := active[i-1]
 				b.WriteString(ansiSep(seg.BG, prev.BG, sep))
 			}
-			b.WriteString(ansi256(seg.FG, seg.BG, seg.Text))
+			b.WriteString(ansi256(seg.FG, seg.BG, styledText(seg)))
 		} else {
 			if i > 0 {
 				b.WriteString(sep)
 			}
-			b.WriteString(ansi256(seg.FG, seg.BG, seg.Text))
+			b.WriteString(ansi256(seg.FG, seg.BG, styledText(seg)))
 		}
 
 		if seg.Link != "" && !inTmux {
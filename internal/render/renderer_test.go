@@ -416,3 +416,48 @@ func TestRenderSegmentOrder(t *testing.T) {
 		t.Error("expected segments rendered in order: model before directory")
 	}
 }
+
+func TestRenderSpansColorRuns(t *testing.T) {
+	seg := segments.Segment{
+		Name: "git", Text: "main ↑1", FG: "15", BG: "22", Enabled: true,
+		Spans: []segments.Span{{Text: "main "}, {Text: "↑1", FG: "214"}},
+	}
+
+	out := Render([]segments.Segment{seg}, true, 120)
+	if !strings.Contains(out, " main \033[38;5;214m↑1\033[38;5;15m ") {
+		t.Errorf("expected span colored and segment FG restored, got %q", out)
+	}
+
+	// Compact mode falls back to the plain text.
+	out = Render([]segments.Segment{seg}, true, 5)
+	if strings.Contains(out, "214") {
+		t.Errorf("expected spans dropped in compact mode, got %q", out)
+	}
+}
+
+func TestRenderSpanLinks(t *testing.T) {
+	orig := inTmux
+	defer func() { inTmux = orig }()
+	seg := segments.Segment{
+		Name: "remote", Text: "owner/repo PR", FG: "15", BG: "22", Enabled: true,
+		Spans: []segments.Span{
+			{Text: "owner/repo", Link: "https://github.com/owner/repo/tree/main"},
+			{Text: " "},
+			{Text: "PR", Link: "https://github.com/owner/repo/compare/main?expand=1"},
+		},
+	}
+
+	inTmux = false
+	out := RenderRight([]segments.Segment{seg}, true)
+	want := osc8Open("https://github.com/owner/repo/tree/main") + "owner/repo" + osc8CloseStr() + " " +
+		osc8Open("https://github.com/owner/repo/compare/main?expand=1") + "PR" + osc8CloseStr()
+	if !strings.Contains(out, want) {
+		t.Errorf("expected each span linked on its own, got %q", out)
+	}
+
+	inTmux = true
+	out = RenderRight([]segments.Segment{seg}, true)
+	if strings.Contains(out, "\033]8;;") || strings.Contains(out, "https://") {
+		t.Errorf("expected span links dropped in tmux, got %q", out)
+	}
+}
@@ -9,8 +9,10 @@ import (
 )
 
 // Block returns a segment displaying the 5-hour block usage percentage and countdown.
-// Color intensity changes based on usage thresholds: normal (<70%), warning (70-90%), critical (>90%).
-func Block(data *oauth.UsageData, theme themes.Theme) Segment {
+// Colors follow the given thresholds, by default thresholds.block in the
+// config: normal (<70%), warning (70-90%) and critical (>=90%). The countdown
+// is measured from now.
+func Block(data *oauth.UsageData, now time.Time, thresholds Thresholds, theme themes.Theme) Segment {
 	colors := theme.Segments["block"]
 
 	if data == nil {
@@ -23,16 +25,10 @@ func Block(data *oauth.UsageData, theme themes.Theme) Segment {
 		}
 	}
 
-	// Select color based on usage threshold
-	switch {
-	case data.BlockPercentage >= usageCriticalThreshold:
-		colors = theme.Segments["critical"]
-	case data.BlockPercentage >= usageWarningThreshold:
-		colors = theme.Segments["warning"]
-	}
+	colors = thresholdColors(theme, thresholds, data.BlockPercentage, "block")
 
 	// Format countdown
-	remaining := time.Until(data.BlockResetTime)
+	remaining := data.BlockResetTime.Sub(now)
 	countdown := formatCountdown(remaining)
 
 	text := fmt.Sprintf("%.0f%% %s", data.BlockPercentage, countdown)
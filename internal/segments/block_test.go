@@ -16,7 +16,7 @@ func TestBlockPercentageDisplay(t *testing.T) {
 		BlockResetTime:  time.Now().Add(2*time.Hour + 13*time.Minute),
 	}
 
-	seg := Block(data, theme)
+	seg := Block(data, time.Now(), defaultThresholds("block"), theme)
 	if !seg.Enabled {
 		t.Error("expected segment enabled")
 	}
@@ -37,7 +37,7 @@ func TestBlockCountdownFormat(t *testing.T) {
 		BlockResetTime:  time.Now().Add(2*time.Hour + 13*time.Minute),
 	}
 
-	seg := Block(data, theme)
+	seg := Block(data, time.Now(), defaultThresholds("block"), theme)
 	if !seg.Enabled {
 		t.Error("expected segment enabled")
 	}
@@ -55,7 +55,7 @@ func TestBlockColorThresholdNormal(t *testing.T) {
 		BlockResetTime:  time.Now().Add(3 * time.Hour),
 	}
 
-	seg := Block(data, theme)
+	seg := Block(data, time.Now(), defaultThresholds("block"), theme)
 	expectedColors := theme.Segments["block"]
 	if seg.BG != expectedColors.BG {
 		t.Errorf("expected normal BG %q, got %q", expectedColors.BG, seg.BG)
@@ -70,7 +70,7 @@ func TestBlockColorThresholdWarning(t *testing.T) {
 		BlockResetTime:  time.Now().Add(1 * time.Hour),
 	}
 
-	seg := Block(data, theme)
+	seg := Block(data, time.Now(), defaultThresholds("block"), theme)
 	expectedColors := theme.Segments["warning"]
 	if seg.BG != expectedColors.BG {
 		t.Errorf("expected warning BG %q, got %q", expectedColors.BG, seg.BG)
@@ -85,7 +85,7 @@ func TestBlockColorThresholdCritical(t *testing.T) {
 		BlockResetTime:  time.Now().Add(30 * time.Minute),
 	}
 
-	seg := Block(data, theme)
+	seg := Block(data, time.Now(), defaultThresholds("block"), theme)
 	expectedColors := theme.Segments["critical"]
 	if seg.BG != expectedColors.BG {
 		t.Errorf("expected critical BG %q, got %q", expectedColors.BG, seg.BG)
@@ -95,7 +95,7 @@ func TestBlockColorThresholdCritical(t *testing.T) {
 func TestBlockNilData(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Block(nil, theme)
+	seg := Block(nil, time.Now(), defaultThresholds("block"), theme)
 	if !seg.Enabled {
 		t.Error("expected segment enabled with placeholder")
 	}
@@ -113,7 +113,7 @@ func TestBlockStaleIndicator(t *testing.T) {
 		IsStale:         true,
 	}
 
-	seg := Block(data, theme)
+	seg := Block(data, time.Now(), defaultThresholds("block"), theme)
 	if !seg.Enabled {
 		t.Error("expected segment enabled")
 	}
@@ -131,8 +131,18 @@ func TestBlockPastResetTime(t *testing.T) {
 		BlockResetTime:  time.Now().Add(-1 * time.Hour),
 	}
 
-	seg := Block(data, theme)
+	seg := Block(data, time.Now(), defaultThresholds("block"), theme)
 	if !seg.Enabled {
 		t.Error("expected segment enabled")
 	}
 }
+
+func TestBlockCountdownFromFixedClock(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	data := &oauth.UsageData{BlockPercentage: 40, BlockResetTime: now.Add(90 * time.Minute)}
+
+	if seg := Block(data, now, defaultThresholds("block"), theme); seg.Text != "40% 1h30m" {
+		t.Errorf("expected countdown measured from the given clock, got %q", seg.Text)
+	}
+}
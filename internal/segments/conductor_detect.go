@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/rbarcante/conductor-powerline/internal/claudehome"
 )
 
 // ConductorStatus represents the detection state of the conductor plugin.
@@ -30,19 +32,15 @@ type installedPluginsFile struct {
 }
 
 // DetectConductorStatus checks the conductor plugin installation state.
-// baseDir is the user's home directory (empty string uses os.UserHomeDir).
+// baseDir is the user's home directory; an empty string uses the Claude config
+// directory ($CLAUDE_CONFIG_DIR or ~/.claude).
 // projectDir is the current working directory to check for a conductor/ folder.
 func DetectConductorStatus(baseDir string, projectDir string) ConductorStatus {
-	if baseDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return ConductorNone
-		}
-		baseDir = home
+	claudeDir := claudehome.Resolve(baseDir)
+	if claudeDir == "" {
+		return ConductorNone
 	}
 
-	claudeDir := filepath.Join(baseDir, ".claude")
-
 	inRegistry := detectViaRegistry(claudeDir)
 	if inRegistry {
 		if projectHasConductor(projectDir) {
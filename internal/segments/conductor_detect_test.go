@@ -164,6 +164,23 @@ func TestDetect_EmptyBaseUsesHomeDir(t *testing.T) {
 	_ = status
 }
 
+func TestDetect_EmptyBaseHonorsClaudeConfigDir(t *testing.T) {
+	// makeRegistry writes under <base>/.claude, which is the directory
+	// CLAUDE_CONFIG_DIR points at in this layout.
+	base := t.TempDir()
+	project := t.TempDir()
+	makeRegistry(t, base, map[string]any{
+		"conductor@claude-conductor": []any{},
+	})
+	t.Setenv("CLAUDE_CONFIG_DIR", filepath.Join(base, ".claude"))
+	t.Setenv("HOME", t.TempDir())
+
+	status := DetectConductorStatus("", project)
+	if status != ConductorInstalled {
+		t.Errorf("expected ConductorInstalled via CLAUDE_CONFIG_DIR, got %d", status)
+	}
+}
+
 func TestDetect_ProjectConductorIsFile(t *testing.T) {
 	base := t.TempDir()
 	project := t.TempDir()
@@ -0,0 +1,20 @@
+package segments
+
+import "github.com/rbarcante/conductor-powerline/internal/themes"
+
+// ConfigWarning returns a segment flagging configuration problems. Details
+// are in the debug log and `conductor-powerline config validate`.
+// Returns a disabled segment when there are no problems.
+func ConfigWarning(problems int, theme themes.Theme) Segment {
+	if problems == 0 {
+		return Segment{Name: "config", Enabled: false}
+	}
+	colors := theme.Segments["warning"]
+	return Segment{
+		Name:    "config",
+		Text:    "⚠ config",
+		FG:      colors.FG,
+		BG:      colors.BG,
+		Enabled: true,
+	}
+}
@@ -0,0 +1,30 @@
+package segments
+
+import (
+	"testing"
+
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+)
+
+func TestConfigWarning(t *testing.T) {
+	theme, _ := themes.Get("dark")
+
+	seg := ConfigWarning(2, theme)
+	if !seg.Enabled {
+		t.Fatal("expected segment enabled when there are problems")
+	}
+	if seg.Text != "⚠ config" {
+		t.Errorf("expected '⚠ config', got %q", seg.Text)
+	}
+	if seg.BG != theme.Segments["warning"].BG {
+		t.Errorf("expected warning colors, got BG %q", seg.BG)
+	}
+}
+
+func TestConfigWarningNoProblems(t *testing.T) {
+	theme, _ := themes.Get("dark")
+
+	if seg := ConfigWarning(0, theme); seg.Enabled {
+		t.Error("expected segment disabled without problems")
+	}
+}
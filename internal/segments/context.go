@@ -8,34 +8,26 @@ import (
 
 // Context returns a segment displaying the context window usage percentage.
 // Returns a disabled segment if percent is -1 (missing data).
-// Icon and color change dynamically based on usage thresholds.
-func Context(percent int, nerdFonts bool, theme themes.Theme) Segment {
+// Icon and color change dynamically based on the given thresholds: the icon is
+// empty below the first level, full at the highest, and half-filled in between.
+func Context(percent int, nerdFonts bool, thresholds Thresholds, theme themes.Theme) Segment {
 	if percent < 0 {
 		return Segment{Name: "context", Enabled: false}
 	}
 
-	// Select color based on usage threshold
-	var colorKey string
-	switch {
-	case percent > contextCriticalThreshold:
-		colorKey = "critical"
-	case percent >= contextWarningThreshold:
-		colorKey = "warning"
-	default:
-		colorKey = "context"
-	}
-	colors := theme.Segments[colorKey]
+	colors := thresholdColors(theme, thresholds, float64(percent), "context")
 
-	// Select icon based on threshold
+	// Select icon based on threshold level
 	var icon string
 	if nerdFonts {
+		level := thresholds.Level(float64(percent))
 		switch {
-		case percent > contextCriticalThreshold:
+		case level == 0:
+			icon = "○"
+		case level == len(thresholds):
 			icon = "●"
-		case percent >= contextWarningThreshold:
-			icon = "◐"
 		default:
-			icon = "○"
+			icon = "◐"
 		}
 	} else {
 		icon = "CTX"
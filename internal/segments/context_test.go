@@ -9,7 +9,7 @@ import (
 func TestContextNormalRange(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Context(30, true, theme)
+	seg := Context(30, true, defaultThresholds("context"), theme)
 	if !seg.Enabled {
 		t.Error("expected segment enabled")
 	}
@@ -30,7 +30,7 @@ func TestContextNormalRange(t *testing.T) {
 func TestContextWarningRange(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Context(65, true, theme)
+	seg := Context(65, true, defaultThresholds("context"), theme)
 	if !seg.Enabled {
 		t.Error("expected segment enabled")
 	}
@@ -46,7 +46,7 @@ func TestContextWarningRange(t *testing.T) {
 func TestContextCriticalRange(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Context(85, true, theme)
+	seg := Context(85, true, defaultThresholds("context"), theme)
 	if !seg.Enabled {
 		t.Error("expected segment enabled")
 	}
@@ -62,7 +62,7 @@ func TestContextCriticalRange(t *testing.T) {
 func TestContextBoundary50(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Context(50, true, theme)
+	seg := Context(50, true, defaultThresholds("context"), theme)
 	// 50% should be warning range
 	expectedColors := theme.Segments["warning"]
 	if seg.BG != expectedColors.BG {
@@ -76,7 +76,7 @@ func TestContextBoundary50(t *testing.T) {
 func TestContextBoundary80(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Context(80, true, theme)
+	seg := Context(80, true, defaultThresholds("context"), theme)
 	// 80% should still be warning (> 80% is critical)
 	expectedColors := theme.Segments["warning"]
 	if seg.BG != expectedColors.BG {
@@ -87,7 +87,7 @@ func TestContextBoundary80(t *testing.T) {
 func TestContextBoundary81(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Context(81, true, theme)
+	seg := Context(81, true, defaultThresholds("context"), theme)
 	// 81% should be critical
 	expectedColors := theme.Segments["critical"]
 	if seg.BG != expectedColors.BG {
@@ -98,7 +98,7 @@ func TestContextBoundary81(t *testing.T) {
 func TestContextZeroPercent(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Context(0, true, theme)
+	seg := Context(0, true, defaultThresholds("context"), theme)
 	if !seg.Enabled {
 		t.Error("expected segment enabled at 0%")
 	}
@@ -110,7 +110,7 @@ func TestContextZeroPercent(t *testing.T) {
 func TestContext100Percent(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Context(100, true, theme)
+	seg := Context(100, true, defaultThresholds("context"), theme)
 	if !seg.Enabled {
 		t.Error("expected segment enabled at 100%")
 	}
@@ -126,7 +126,7 @@ func TestContext100Percent(t *testing.T) {
 func TestContextMissingData(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Context(-1, true, theme)
+	seg := Context(-1, true, defaultThresholds("context"), theme)
 	if seg.Enabled {
 		t.Error("expected segment disabled when percent is -1")
 	}
@@ -135,7 +135,7 @@ func TestContextMissingData(t *testing.T) {
 func TestContextTextFallback(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Context(30, false, theme)
+	seg := Context(30, false, defaultThresholds("context"), theme)
 	if seg.Text != "CTX 30%" {
 		t.Errorf("Text = %q, want %q (text fallback)", seg.Text, "CTX 30%")
 	}
@@ -144,7 +144,7 @@ func TestContextTextFallback(t *testing.T) {
 func TestContextTextFallbackWarning(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Context(65, false, theme)
+	seg := Context(65, false, defaultThresholds("context"), theme)
 	if seg.Text != "CTX 65%" {
 		t.Errorf("Text = %q, want %q (text fallback warning)", seg.Text, "CTX 65%")
 	}
@@ -153,7 +153,7 @@ func TestContextTextFallbackWarning(t *testing.T) {
 func TestContextTextFallbackCritical(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Context(90, false, theme)
+	seg := Context(90, false, defaultThresholds("context"), theme)
 	if seg.Text != "CTX 90%" {
 		t.Errorf("Text = %q, want %q (text fallback critical)", seg.Text, "CTX 90%")
 	}
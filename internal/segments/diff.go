@@ -0,0 +1,247 @@
+package segments
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+)
+
+// emptyTree is the id of git's empty tree, diffed against in a repository
+// with no commits yet.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// maxUntrackedBytes bounds how much of an untracked file is read to count
+// its lines. Larger files count as touched without lines, like binaries.
+const maxUntrackedBytes = 1 << 20
+
+// DiffOptions configures the diff segment.
+type DiffOptions struct {
+	// Exclude lists paths left out of the counts. A pattern without a slash
+	// matches the file name anywhere ("*.lock"); one with a slash matches
+	// the path from the repository root ("gen/*.go"); a trailing "/"
+	// matches everything below a directory ("vendor/").
+	Exclude []string `json:"exclude"`
+	// UnknownMarker is appended when gitTimeout ran out before every
+	// untracked file was counted.
+	UnknownMarker string `json:"unknownMarker"`
+}
+
+// DefaultDiffOptions returns the diff segment defaults: common lockfiles are
+// excluded.
+func DefaultDiffOptions() DiffOptions {
+	return DiffOptions{
+		Exclude:       []string{"package-lock.json", "yarn.lock", "pnpm-lock.yaml", "go.sum", "Cargo.lock", "poetry.lock", "uv.lock", "Gemfile.lock", "composer.lock"},
+		UnknownMarker: "?",
+	}
+}
+
+// Validate implements Options.
+func (o DiffOptions) Validate() error {
+	for _, pattern := range o.Exclude {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/"), ""); err != nil || pattern == "" {
+			return fmt.Errorf("invalid exclude pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// excluded reports whether the slash-separated path p matches an exclude
+// pattern.
+func (o DiffOptions) excluded(p string) bool {
+	for _, pattern := range o.Exclude {
+		if dir, ok := strings.CutSuffix(pattern, "/"); ok {
+			if p == dir || strings.HasPrefix(p, dir+"/") {
+				return true
+			}
+			continue
+		}
+		name := p
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(p)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffFile is one path changed in the working tree since HEAD.
+type DiffFile struct {
+	// Path is relative to the repository root, with forward slashes.
+	Path    string `json:"path"`
+	Added   int    `json:"added,omitempty"`
+	Deleted int    `json:"deleted,omitempty"`
+	// Binary files have no line counts.
+	Binary    bool `json:"binary,omitempty"`
+	Untracked bool `json:"untracked,omitempty"`
+}
+
+// DiffStat is what the diff segment reads from the repository.
+type DiffStat struct {
+	Files []DiffFile `json:"files,omitempty"`
+	// Partial is set when ctx ended before every untracked file was
+	// counted; Files then lacks the rest.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// gitDiffCache is the diff cached for one repository.
+type gitDiffCache struct {
+	gitCacheKey
+	Stat DiffStat `json:"stat"`
+}
+
+// ReadDiff compares the working tree of the repository containing workspace
+// with HEAD, running git diff --numstat for tracked files and git ls-files
+// for untracked ones, whose lines are counted directly. Both commands and the
+// counting are bounded by ctx. The result is cached in cacheDir on the same
+// terms as git status in ReadGitStatus; an empty cacheDir disables caching.
+// Returns nil outside a repository or when git fails.
+func ReadDiff(ctx context.Context, cacheDir, workspace string) *DiffStat {
+	dir := workspace
+	if dir == "" {
+		dir = "."
+	}
+	repo, ok := findRepo(dir)
+	if !ok {
+		return nil
+	}
+	_, commit, ok := repo.head()
+	if !ok {
+		return nil
+	}
+	key := newGitCacheKey(repo, commit)
+	cachePath := gitCachePath(cacheDir, repo, "-diff")
+	var cached gitDiffCache
+	if loadGitCache(cachePath, &cached) && cached.fresh(key) {
+		return &cached.Stat
+	}
+	if commit == "" {
+		commit = emptyTree
+	}
+
+	out, err := gitCommandRunner(ctx, gitArgs(repo.root, "diff", "--numstat", "--no-renames", "-z", commit)...)
+	if err != nil {
+		return nil
+	}
+	stat := &DiffStat{Files: parseNumstat(out)}
+
+	out, err = gitCommandRunner(ctx, gitArgs(repo.root, "ls-files", "--others", "--exclude-standard", "-z")...)
+	if err != nil {
+		return nil
+	}
+	for _, p := range strings.Split(out, "\x00") {
+		if p == "" {
+			continue
+		}
+		if ctx.Err() != nil {
+			stat.Partial = true
+			break
+		}
+		file := DiffFile{Path: p, Untracked: true}
+		file.Added, file.Binary = countLines(filepath.Join(repo.root, filepath.FromSlash(p)))
+		stat.Files = append(stat.Files, file)
+	}
+	// A partial count is not cached, so the next render tries again.
+	if !stat.Partial {
+		// A failed write only costs running git on the next render.
+		saveGitCache(cachePath, gitDiffCache{gitCacheKey: key, Stat: *stat})
+	}
+	return stat
+}
+
+// parseNumstat parses git diff --numstat -z --no-renames output: one
+// "added\tdeleted\tpath" record per file, NUL-terminated, with "-" counts
+// for binary files.
+func parseNumstat(out string) []DiffFile {
+	var files []DiffFile
+	for _, record := range strings.Split(out, "\x00") {
+		added, rest, ok := strings.Cut(record, "\t")
+		if !ok {
+			continue
+		}
+		deleted, p, ok := strings.Cut(rest, "\t")
+		if !ok || p == "" {
+			continue
+		}
+		file := DiffFile{Path: p}
+		if added == "-" || deleted == "-" {
+			file.Binary = true
+		} else {
+			file.Added, _ = strconv.Atoi(added)
+			file.Deleted, _ = strconv.Atoi(deleted)
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// countLines counts the lines of a text file as git would for an added file,
+// including a last line without a newline. Files that look binary (a NUL in
+// the first 8000 bytes, git's heuristic), exceed maxUntrackedBytes or cannot
+// be read report binary.
+func countLines(name string) (lines int, binary bool) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, true
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxUntrackedBytes+1))
+	if err != nil || len(data) > maxUntrackedBytes || bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return 0, true
+	}
+	lines = bytes.Count(data, []byte{'\n'})
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		lines++
+	}
+	return lines, false
+}
+
+// Diff returns a segment with the lines added and deleted since HEAD and the
+// number of files touched, e.g. "+120 -14 · 5 files", leaving out excluded
+// paths. Its colors follow thresholds, measured in lines added plus deleted,
+// and opts.UnknownMarker follows a partial count. Returns a disabled segment
+// when stat is nil or nothing changed.
+func Diff(stat *DiffStat, opts DiffOptions, thresholds Thresholds, theme themes.Theme) Segment {
+	if stat == nil {
+		return Segment{Name: "diff", Enabled: false}
+	}
+	var added, deleted, files int
+	for _, f := range stat.Files {
+		if opts.excluded(f.Path) {
+			continue
+		}
+		added += f.Added
+		deleted += f.Deleted
+		files++
+	}
+	if files == 0 {
+		return Segment{Name: "diff", Enabled: false}
+	}
+
+	colors := thresholdColors(theme, thresholds, float64(added+deleted), "diff")
+
+	noun := "files"
+	if files == 1 {
+		noun = "file"
+	}
+	text := fmt.Sprintf("+%d -%d · %d %s", added, deleted, files, noun)
+	if stat.Partial {
+		text += opts.UnknownMarker
+	}
+	return Segment{
+		Name:    "diff",
+		Text:    text,
+		FG:      colors.FG,
+		BG:      colors.BG,
+		Enabled: true,
+	}
+}
@@ -0,0 +1,226 @@
+package segments
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+)
+
+func TestParseNumstat(t *testing.T) {
+	out := "10\t2\tmain.go\x00-\t-\tlogo.png\x000\t5\tdir/with\ttab.txt\x00"
+
+	got := parseNumstat(out)
+
+	want := []DiffFile{
+		{Path: "main.go", Added: 10, Deleted: 2},
+		{Path: "logo.png", Binary: true},
+		{Path: "dir/with\ttab.txt", Deleted: 5},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseNumstat = %+v, want %+v", got, want)
+	}
+	if got := parseNumstat(""); len(got) != 0 {
+		t.Errorf("expected no files for empty output, got %+v", got)
+	}
+}
+
+func TestReadDiff(t *testing.T) {
+	repo := writeRepo(t, nil)
+	writeFiles(t, repo, map[string]string{
+		"notes.txt":    "one\ntwo\nthree",
+		"empty.txt":    "",
+		"data.bin":     "a\x00b\n",
+		"sub/more.txt": "x\n",
+	})
+	calls := mockGit(t, map[string]string{
+		"diff":     "3\t1\tmain.go\x00",
+		"ls-files": "notes.txt\x00empty.txt\x00data.bin\x00sub/more.txt\x00missing.txt\x00",
+	})
+
+	stat := ReadDiff(context.Background(), "", filepath.Join(repo, "sub"))
+
+	want := []DiffFile{
+		{Path: "main.go", Added: 3, Deleted: 1},
+		{Path: "notes.txt", Added: 3, Untracked: true},
+		{Path: "empty.txt", Untracked: true},
+		{Path: "data.bin", Binary: true, Untracked: true},
+		{Path: "sub/more.txt", Added: 1, Untracked: true},
+		{Path: "missing.txt", Binary: true, Untracked: true},
+	}
+	if stat == nil || !slices.Equal(stat.Files, want) {
+		t.Fatalf("ReadDiff = %+v, want %+v", stat, want)
+	}
+	// Paths are relative to the root, so git runs there rather than in
+	// the subdirectory.
+	if got := strings.Join((*calls)[0], " "); got != "-C "+repo+" diff --numstat --no-renames -z "+headCommit {
+		t.Errorf("unexpected diff command %q", got)
+	}
+}
+
+func TestReadDiffUnbornAndFailures(t *testing.T) {
+	repo := writeRepo(t, map[string]string{"HEAD": "ref: refs/heads/new\n"})
+	calls := mockGit(t, map[string]string{"diff": "", "ls-files": ""})
+
+	if stat := ReadDiff(context.Background(), "", repo); stat == nil || len(stat.Files) != 0 {
+		t.Errorf("expected an empty diff, got %+v", stat)
+	}
+	if args := (*calls)[0]; args[len(args)-1] != emptyTree {
+		t.Errorf("expected a diff against the empty tree without commits, got %v", args)
+	}
+
+	mockGit(t, map[string]string{"ls-files": ""})
+	if stat := ReadDiff(context.Background(), "", repo); stat != nil {
+		t.Errorf("expected nil when git diff fails, got %+v", stat)
+	}
+	if stat := ReadDiff(context.Background(), "", t.TempDir()); stat != nil {
+		t.Errorf("expected nil outside a repository, got %+v", stat)
+	}
+}
+
+func TestReadDiffCache(t *testing.T) {
+	repo := writeRepo(t, map[string]string{"index": "v1"})
+	writeFiles(t, repo, map[string]string{"new.txt": "a\nb\n"})
+	calls := mockGit(t, map[string]string{"diff": "1\t0\tmain.go\x00", "ls-files": "new.txt\x00"})
+	cacheDir := t.TempDir()
+
+	first := ReadDiff(context.Background(), cacheDir, repo)
+	second := ReadDiff(context.Background(), cacheDir, repo)
+	if len(*calls) != 2 {
+		t.Fatalf("expected git to run once per command for an unchanged index, ran %d commands", len(*calls))
+	}
+	if second == nil || !slices.Equal(first.Files, second.Files) {
+		t.Errorf("cached diff %+v differs from %+v", second, first)
+	}
+
+	// Staging a file rewrites the index.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(repo, ".git", "index"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	ReadDiff(context.Background(), cacheDir, repo)
+	if len(*calls) != 4 {
+		t.Errorf("expected git to run again after the index changed, ran %d commands", len(*calls))
+	}
+}
+
+func TestReadDiffStopsAtDeadline(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	repo := writeRepo(t, nil)
+	writeFiles(t, repo, map[string]string{"a.txt": "a\n", "b.txt": "b\n"})
+	calls := mockGit(t, map[string]string{"diff": "1\t0\tmain.go\x00", "ls-files": "a.txt\x00b.txt\x00"})
+	cacheDir := t.TempDir()
+
+	// The mock answers regardless of ctx, so the counting is what stops.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stat := ReadDiff(ctx, cacheDir, repo)
+
+	if stat == nil || !stat.Partial || len(stat.Files) != 1 {
+		t.Fatalf("expected only the tracked file before the deadline, got %+v", stat)
+	}
+	if seg := Diff(stat, DefaultDiffOptions(), nil, theme); seg.Text != "+1 -0 · 1 file?" {
+		t.Errorf("expected the unknown marker on a partial count, got %q", seg.Text)
+	}
+
+	// A partial count is not cached.
+	if stat := ReadDiff(context.Background(), cacheDir, repo); stat == nil || stat.Partial || len(stat.Files) != 3 {
+		t.Errorf("expected a full count once time allows, got %+v", stat)
+	}
+	if len(*calls) != 4 {
+		t.Errorf("expected git to run again after a partial count, ran %d commands", len(*calls))
+	}
+}
+
+func TestDiffSegment(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	stat := &DiffStat{Files: []DiffFile{
+		{Path: "main.go", Added: 40, Deleted: 12},
+		{Path: "logo.png", Binary: true},
+		{Path: "web/package-lock.json", Added: 900, Deleted: 300},
+		{Path: "notes.txt", Added: 3, Untracked: true},
+	}}
+
+	thresholds := Thresholds{{At: 200, Color: "warning"}, {At: 500, Color: "critical"}}
+	seg := Diff(stat, DefaultDiffOptions(), thresholds, theme)
+
+	if !seg.Enabled || seg.Text != "+43 -12 · 3 files" {
+		t.Errorf("expected lockfile excluded, got %+v", seg)
+	}
+	if seg.BG != theme.Segments["diff"].BG {
+		t.Errorf("expected diff colors below the warning level, got BG %s", seg.BG)
+	}
+
+	tests := []struct {
+		name       string
+		opts       DiffOptions
+		thresholds Thresholds
+		wantBG     string
+		wantTxt    string
+	}{
+		{"warning", DiffOptions{}, Thresholds{{At: 50, Color: "warning"}, {At: 2000, Color: "critical"}}, theme.Segments["warning"].BG, "+943 -312 · 4 files"},
+		{"critical", DiffOptions{Exclude: []string{"*.png"}}, thresholds, theme.Segments["critical"].BG, "+943 -312 · 3 files"},
+		{"no levels", DiffOptions{}, nil, theme.Segments["diff"].BG, "+943 -312 · 4 files"},
+		{"single file", DiffOptions{Exclude: []string{"web/", "*.png", "notes.*"}}, thresholds, theme.Segments["diff"].BG, "+40 -12 · 1 file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seg := Diff(stat, tt.opts, tt.thresholds, theme)
+			if seg.Text != tt.wantTxt || seg.BG != tt.wantBG {
+				t.Errorf("got %q on %s, want %q on %s", seg.Text, seg.BG, tt.wantTxt, tt.wantBG)
+			}
+		})
+	}
+
+	if seg := Diff(&DiffStat{}, DefaultDiffOptions(), thresholds, theme); seg.Enabled {
+		t.Error("expected segment disabled for a clean tree")
+	}
+	if seg := Diff(&DiffStat{Files: []DiffFile{{Path: "go.sum", Added: 2}}}, DefaultDiffOptions(), thresholds, theme); seg.Enabled {
+		t.Error("expected segment disabled when only excluded files changed")
+	}
+	if seg := Diff(nil, DefaultDiffOptions(), thresholds, theme); seg.Enabled {
+		t.Error("expected segment disabled without diff data")
+	}
+}
+
+func TestDiffExclude(t *testing.T) {
+	opts := DiffOptions{Exclude: []string{"*.lock", "gen/*.go", "vendor/"}}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"Cargo.lock", true},
+		{"crates/a/Cargo.lock", true},
+		{"gen/api.go", true},
+		{"gen/sub/api.go", false},
+		{"src/gen/api.go", false},
+		{"vendor", true},
+		{"vendor/x/y.go", true},
+		{"vendored/y.go", false},
+		{"main.go", false},
+	}
+	for _, tt := range tests {
+		if got := opts.excluded(tt.path); got != tt.want {
+			t.Errorf("excluded(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDiffOptionsValidate(t *testing.T) {
+	if err := DefaultDiffOptions().Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	for _, opts := range []DiffOptions{
+		{Exclude: []string{"[a-"}},
+		{Exclude: []string{""}},
+	} {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+}
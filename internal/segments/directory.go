@@ -1,6 +1,7 @@
 package segments
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -8,12 +9,46 @@ import (
 	"github.com/rbarcante/conductor-powerline/internal/themes"
 )
 
+// subPathSeparator joins the project name and the sub-path.
+const subPathSeparator = " › "
+
+// DirectoryOptions configures the directory segment.
+type DirectoryOptions struct {
+	// MaxLength truncates longer project names with "…". Zero means no limit.
+	MaxLength int `json:"maxLength"`
+	// Depth is how many trailing components of the sub-path below the
+	// project are shown; deeper paths are prefixed with "…/". Zero hides
+	// the sub-path.
+	Depth int `json:"depth"`
+}
+
+// DefaultDirectoryOptions returns the directory segment defaults.
+func DefaultDirectoryOptions() DirectoryOptions {
+	return DirectoryOptions{Depth: 2}
+}
+
+// Validate implements Options.
+func (o DirectoryOptions) Validate() error {
+	if o.MaxLength < 0 {
+		return errors.New("maxLength must not be negative")
+	}
+	if o.Depth < 0 {
+		return errors.New("depth must not be negative")
+	}
+	return nil
+}
+
 // Directory returns a segment displaying the project/directory name.
 // It extracts the base name from the workspace path, falling back to cwd.
-func Directory(workspace string, theme themes.Theme) Segment {
+// When currentDir is below the workspace the sub-path is appended, e.g.
+// "repo › services/api".
+func Directory(workspace, currentDir string, opts DirectoryOptions, theme themes.Theme) Segment {
 	colors := theme.Segments["directory"]
 
-	name := extractDirName(workspace)
+	name := truncateRunes(extractDirName(workspace), opts.MaxLength)
+	if sub := subPath(workspace, currentDir, opts.Depth); sub != "" {
+		name += subPathSeparator + sub
+	}
 
 	return Segment{
 		Name:    "directory",
@@ -40,3 +75,33 @@ func extractDirName(workspace string) string {
 	workspace = strings.TrimRight(workspace, "/")
 	return filepath.Base(workspace)
 }
+
+// subPath returns currentDir relative to workspace, keeping the last depth
+// components. Returns "" when currentDir is the workspace, lies outside it,
+// or depth is zero.
+func subPath(workspace, currentDir string, depth int) string {
+	if workspace == "" || currentDir == "" || depth <= 0 {
+		return ""
+	}
+	rel, err := filepath.Rel(workspace, currentDir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) > depth {
+		return "…/" + strings.Join(parts[len(parts)-depth:], "/")
+	}
+	return strings.Join(parts, "/")
+}
+
+// truncateRunes shortens s to max runes, ending in "…". max <= 0 disables it.
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if max <= 0 || len(runes) <= max {
+		return s
+	}
+	if max == 1 {
+		return "…"
+	}
+	return string(runes[:max-1]) + "…"
+}
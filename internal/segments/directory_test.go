@@ -8,7 +8,7 @@ import (
 
 func TestDirectoryFromWorkspace(t *testing.T) {
 	theme, _ := themes.Get("dark")
-	seg := Directory("/Users/dev/my-project", theme)
+	seg := Directory("/Users/dev/my-project", "", DefaultDirectoryOptions(), theme)
 
 	if !seg.Enabled {
 		t.Error("expected segment enabled")
@@ -26,7 +26,7 @@ func TestDirectoryFromWorkspace(t *testing.T) {
 
 func TestDirectoryNestedPath(t *testing.T) {
 	theme, _ := themes.Get("dark")
-	seg := Directory("/home/user/projects/deep/nested/repo", theme)
+	seg := Directory("/home/user/projects/deep/nested/repo", "", DefaultDirectoryOptions(), theme)
 
 	if seg.Text != "repo" {
 		t.Errorf("expected text 'repo', got %q", seg.Text)
@@ -35,7 +35,7 @@ func TestDirectoryNestedPath(t *testing.T) {
 
 func TestDirectoryRootPath(t *testing.T) {
 	theme, _ := themes.Get("dark")
-	seg := Directory("/", theme)
+	seg := Directory("/", "", DefaultDirectoryOptions(), theme)
 
 	if seg.Text != "/" {
 		t.Errorf("expected text '/', got %q", seg.Text)
@@ -44,7 +44,7 @@ func TestDirectoryRootPath(t *testing.T) {
 
 func TestDirectoryEmptyWorkspace(t *testing.T) {
 	theme, _ := themes.Get("dark")
-	seg := Directory("", theme)
+	seg := Directory("", "", DefaultDirectoryOptions(), theme)
 
 	// Should fall back to something (cwd base name)
 	if seg.Text == "" {
@@ -57,9 +57,47 @@ func TestDirectoryEmptyWorkspace(t *testing.T) {
 
 func TestDirectoryTrailingSlash(t *testing.T) {
 	theme, _ := themes.Get("dark")
-	seg := Directory("/Users/dev/project/", theme)
+	seg := Directory("/Users/dev/project/", "", DefaultDirectoryOptions(), theme)
 
 	if seg.Text != "project" {
 		t.Errorf("expected text 'project', got %q", seg.Text)
 	}
 }
+
+func TestDirectoryMaxLength(t *testing.T) {
+	theme, _ := themes.Get("dark")
+
+	seg := Directory("/home/user/conductor-powerline", "", DirectoryOptions{MaxLength: 9}, theme)
+	if seg.Text != "conducto…" {
+		t.Errorf("expected 'conducto…', got %q", seg.Text)
+	}
+
+	seg = Directory("/home/user/api", "", DirectoryOptions{MaxLength: 9}, theme)
+	if seg.Text != "api" {
+		t.Errorf("expected short names untouched, got %q", seg.Text)
+	}
+}
+
+func TestDirectorySubPath(t *testing.T) {
+	theme, _ := themes.Get("dark")
+
+	tests := []struct {
+		current string
+		depth   int
+		want    string
+	}{
+		{"/home/dev/repo/services/api", 2, "repo › services/api"},
+		{"/home/dev/repo/services/api/internal/http", 2, "repo › …/internal/http"},
+		{"/home/dev/repo/services/api", 1, "repo › …/api"},
+		{"/home/dev/repo/services/api", 0, "repo"},
+		{"/home/dev/repo", 2, "repo"},
+		{"/home/dev/other", 2, "repo"},
+		{"/home/dev/repo-old/x", 2, "repo"},
+	}
+	for _, tt := range tests {
+		seg := Directory("/home/dev/repo", tt.current, DirectoryOptions{Depth: tt.depth}, theme)
+		if seg.Text != tt.want {
+			t.Errorf("Directory(%q, depth %d) = %q, want %q", tt.current, tt.depth, seg.Text, tt.want)
+		}
+	}
+}
@@ -1,9 +1,21 @@
 package segments
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/rbarcante/conductor-powerline/internal/atomicfile"
 	"github.com/rbarcante/conductor-powerline/internal/themes"
 )
 
@@ -14,34 +26,548 @@ const BranchIcon = "\ue0a0"
 // It is a package-level variable to allow testing with mocks.
 var gitCommandRunner = runGitCommand
 
-// Git returns a segment displaying the current git branch and dirty state.
-// When workspace is non-empty, git commands target that directory via -C.
-// Returns a disabled segment if git is unavailable or not in a repo.
-func Git(workspace string, theme themes.Theme) Segment {
-	colors := theme.Segments["git"]
+// GitIndicator configures how one git count is shown, e.g. "↑2".
+type GitIndicator struct {
+	// Icon precedes the count. Empty hides the indicator.
+	Icon string `json:"icon"`
+	// Color is a theme color key such as "warning", or a 256-color code.
+	// Empty keeps the segment's foreground.
+	Color string `json:"color"`
+}
+
+// GitOptions configures the git segment.
+type GitOptions struct {
+	// DirtyMarker is appended when the tree has changes. Empty hides it.
+	DirtyMarker string `json:"dirtyMarker"`
+	// UnknownMarker is appended instead of the changes when git status did
+	// not finish within the git timeout. Empty hides it.
+	UnknownMarker string `json:"unknownMarker"`
+	// StripPrefixes are removed from the start of the branch name, e.g.
+	// "feature/". The first match wins.
+	StripPrefixes []string `json:"stripPrefixes"`
+	// IssuePattern is a regular expression finding an issue key in the
+	// branch name, e.g. "[A-Z]+-[0-9]+". The first capture group is the key
+	// if there is one, else the whole match. Empty disables it.
+	IssuePattern string `json:"issuePattern"`
+	// IssueURL links the issue key to the issue, with {{key}} replaced by
+	// the key, e.g. "https://jira.example.com/browse/{{key}}".
+	IssueURL string `json:"issueURL"`
+	// MaxBranchLength shortens the branch name, or the part after the issue
+	// key, with "…". Zero means no limit.
+	MaxBranchLength int          `json:"maxBranchLength"`
+	Ahead           GitIndicator `json:"ahead"`
+	Behind          GitIndicator `json:"behind"`
+	Staged          GitIndicator `json:"staged"`
+	Modified        GitIndicator `json:"modified"`
+	Untracked       GitIndicator `json:"untracked"`
+	Conflicted      GitIndicator `json:"conflicted"`
+	Stash           GitIndicator `json:"stash"`
+}
+
+// DefaultGitOptions returns the git segment defaults: every indicator shown
+// in the segment's colors, conflicts in the critical color.
+func DefaultGitOptions() GitOptions {
+	return GitOptions{
+		UnknownMarker: "?",
+		Ahead:         GitIndicator{Icon: "↑"},
+		Behind:        GitIndicator{Icon: "↓"},
+		Staged:        GitIndicator{Icon: "●"},
+		Modified:      GitIndicator{Icon: "✚"},
+		Untracked:     GitIndicator{Icon: "…"},
+		Conflicted:    GitIndicator{Icon: "✖", Color: "critical"},
+		Stash:         GitIndicator{Icon: "⚑"},
+	}
+}
+
+// Validate implements Options.
+func (o GitOptions) Validate() error {
+	if _, err := regexp.Compile(o.IssuePattern); err != nil {
+		return fmt.Errorf("issuePattern: %w", err)
+	}
+	if o.IssueURL != "" && !strings.Contains(o.IssueURL, issueKeyPlaceholder) {
+		return fmt.Errorf("issueURL must contain %s, got %q", issueKeyPlaceholder, o.IssueURL)
+	}
+	if o.MaxBranchLength < 0 {
+		return errors.New("maxBranchLength must not be negative")
+	}
+	for name, ind := range map[string]GitIndicator{
+		"ahead": o.Ahead, "behind": o.Behind, "staged": o.Staged, "modified": o.Modified,
+		"untracked": o.Untracked, "conflicted": o.Conflicted, "stash": o.Stash,
+	} {
+		if !validColor(ind.Color) {
+			return fmt.Errorf("%s.color must be a theme color such as \"warning\" or a 256-color code, got %q", name, ind.Color)
+		}
+	}
+	return nil
+}
+
+// validColor reports whether color is empty, a 256-color code, or a color
+// key every theme defines.
+func validColor(color string) bool {
+	if color == "" {
+		return true
+	}
+	if n, err := strconv.Atoi(color); err == nil {
+		return n >= 0 && n <= 255
+	}
+	theme, _ := themes.Get("dark")
+	_, ok := theme.Segments[color]
+	return ok
+}
+
+// GitStatus is the repository state the git segment displays. The branch
+// and any operation in progress are read from the git directory; the
+// changes come from git status.
+type GitStatus struct {
+	// Branch is the branch name, or in detached HEAD the exact tag or short
+	// commit SHA.
+	Branch   string `json:"branch"`
+	Detached bool   `json:"detached,omitempty"`
+	// Worktree names the linked worktree the workspace is in; empty in the
+	// main working tree.
+	Worktree string `json:"worktree,omitempty"`
+	// Superproject is the directory name of the repository the workspace is
+	// a submodule of, and Submodule the submodule's path within it.
+	Superproject string `json:"superproject,omitempty"`
+	Submodule    string `json:"submodule,omitempty"`
+	GitChanges
+	// ChangesUnknown is set when git status timed out, so GitChanges is
+	// empty without the tree being clean.
+	ChangesUnknown bool `json:"changesUnknown,omitempty"`
+	// Operation is the in-progress rebase, am, merge, cherry-pick, revert
+	// or bisect, if any. Step and Steps count rebase and am progress.
+	Operation string `json:"operation,omitempty"`
+	Step      int    `json:"step,omitempty"`
+	Steps     int    `json:"steps,omitempty"`
+}
+
+// GitChanges is what git status --porcelain=v2 reports: tracking state,
+// changed files and stashes.
+type GitChanges struct {
+	Upstream   string `json:"upstream,omitempty"`
+	Ahead      int    `json:"ahead,omitempty"`
+	Behind     int    `json:"behind,omitempty"`
+	Staged     int    `json:"staged,omitempty"`
+	Modified   int    `json:"modified,omitempty"`
+	Untracked  int    `json:"untracked,omitempty"`
+	Conflicted int    `json:"conflicted,omitempty"`
+	Stashes    int    `json:"stashes,omitempty"`
+}
+
+// Dirty reports whether the working tree or index has any changes.
+func (c GitChanges) Dirty() bool {
+	return c.Staged+c.Modified+c.Untracked+c.Conflicted > 0
+}
+
+// gitChangesMaxAge bounds how long cached changes are trusted. Editing a
+// tracked file does not touch the index, so an unchanged index alone does
+// not prove an unchanged working tree.
+const gitChangesMaxAge = 5 * time.Second
+
+// gitCacheRetention is how long the cache files of a repository outlive its
+// last use.
+const gitCacheRetention = 7 * 24 * time.Hour
+
+// ReadGitStatus collects the git state of workspace, or of the current
+// directory when workspace is empty. HEAD, refs and operation state are read
+// from the git directory; only the changes need git status, which is run
+// with -C workspace, bounded by ctx, and cached in cacheDir until the index
+// or HEAD changes. An empty cacheDir disables caching. Returns nil if
+// workspace is not in a repo. When git itself fails, the branch is still
+// shown without changes.
+func ReadGitStatus(ctx context.Context, cacheDir, workspace string) *GitStatus {
+	dir := workspace
+	if dir == "" {
+		dir = "."
+	}
+	repo, ok := findRepo(dir)
+	if !ok {
+		return nil
+	}
+	branch, commit, ok := repo.head()
+	if !ok {
+		return nil
+	}
 
-	branch, err := gitCommandRunner(gitArgs(workspace, "rev-parse", "--abbrev-ref", "HEAD")...)
+	status := &GitStatus{Branch: branch}
+	if branch == "" {
+		status.Detached = true
+		status.Branch = repo.exactTag(commit)
+		if status.Branch == "" {
+			status.Branch = shortSHA(commit)
+		}
+	}
+	status.Worktree = repo.worktreeName()
+	if parent, path, ok := repo.superproject(); ok {
+		status.Superproject, status.Submodule = filepath.Base(parent.root), path
+	}
+	readOperation(repo.gitDir, status)
+	changes, err := readGitChanges(ctx, cacheDir, workspace, repo, commit)
+	if err == nil {
+		status.GitChanges = changes
+	}
+	status.ChangesUnknown = err != nil && ctx.Err() != nil
+	return status
+}
+
+// gitCacheKey identifies the repository state a cached git result was
+// computed from.
+type gitCacheKey struct {
+	Index     time.Time `json:"index"`
+	IndexSize int64     `json:"indexSize"`
+	Head      string    `json:"head"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// newGitCacheKey describes the current state of repo checked out at head.
+func newGitCacheKey(repo *gitRepo, head string) gitCacheKey {
+	key := gitCacheKey{Head: head, CheckedAt: time.Now()}
+	if info, err := os.Stat(filepath.Join(repo.gitDir, "index")); err == nil {
+		key.Index, key.IndexSize = info.ModTime(), info.Size()
+	}
+	return key
+}
+
+// fresh reports whether a result cached under k still describes the state
+// in current: the index and HEAD are unchanged and the entry is younger than
+// gitChangesMaxAge.
+func (k gitCacheKey) fresh(current gitCacheKey) bool {
+	return k.Index.Equal(current.Index) && k.IndexSize == current.IndexSize && k.Head == current.Head &&
+		time.Since(k.CheckedAt) < gitChangesMaxAge
+}
+
+// gitCachePath returns the file caching kind for repo in cacheDir, or "" when
+// caching is disabled.
+func gitCachePath(cacheDir string, repo *gitRepo, kind string) string {
+	if cacheDir == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(repo.gitDir))
+	return filepath.Join(cacheDir, "git", hex.EncodeToString(sum[:8])+kind+".json")
+}
+
+// saveGitCache writes v to the cache file at path, if any. Files only pile
+// up when new repositories are cached, so creating one also prunes the files
+// of repositories unused for gitCacheRetention.
+func saveGitCache(path string, v any) {
+	if path == "" {
+		return
+	}
+	_, err := os.Stat(path)
+	_ = atomicfile.WriteJSON(path, v)
+	if os.IsNotExist(err) {
+		atomicfile.Prune(filepath.Dir(path), gitCacheRetention)
+	}
+}
+
+// loadGitCache decodes the cache file at path into v.
+func loadGitCache(path string, v any) bool {
+	if path == "" {
+		return false
+	}
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return Segment{Name: "git", Enabled: false}
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// gitChangesCache is the git status result cached for one repository.
+type gitChangesCache struct {
+	gitCacheKey
+	Changes GitChanges `json:"changes"`
+}
+
+// readGitChanges returns the cached changes while the index and HEAD are
+// unchanged and the entry is younger than gitChangesMaxAge, running git
+// status otherwise.
+func readGitChanges(ctx context.Context, cacheDir, workspace string, repo *gitRepo, head string) (GitChanges, error) {
+	key := newGitCacheKey(repo, head)
+	cachePath := gitCachePath(cacheDir, repo, "")
+	var cached gitChangesCache
+	if loadGitCache(cachePath, &cached) && cached.fresh(key) {
+		return cached.Changes, nil
+	}
+
+	out, err := gitCommandRunner(ctx, gitArgs(workspace, "status", "--porcelain=v2", "--branch", "--show-stash")...)
+	if err != nil {
+		return GitChanges{}, err
+	}
+	entry := gitChangesCache{gitCacheKey: key, Changes: parsePorcelainV2(out)}
+	// A failed write only costs a git status on the next render.
+	saveGitCache(cachePath, entry)
+	return entry.Changes, nil
+}
+
+// readOperation detects an operation in progress from the state files git
+// keeps in gitDir, the same way git's own prompt script does. A rebase
+// reports the branch being rebased rather than the detached HEAD.
+func readOperation(gitDir string, status *GitStatus) {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(gitDir, name))
+		return err == nil
+	}
+	readInt := func(name string) int {
+		n, _ := strconv.Atoi(readGitFile(gitDir, name))
+		return n
 	}
 
-	branch = strings.TrimSpace(branch)
-	text := BranchIcon + " " + branch
+	switch {
+	case exists("rebase-merge"):
+		status.Operation = "rebase"
+		status.Step, status.Steps = readInt("rebase-merge/msgnum"), readInt("rebase-merge/end")
+		rebasedBranch(status, readGitFile(gitDir, "rebase-merge/head-name"))
+	case exists("rebase-apply"):
+		status.Operation = "rebase"
+		if exists("rebase-apply/applying") {
+			status.Operation = "am"
+		}
+		status.Step, status.Steps = readInt("rebase-apply/next"), readInt("rebase-apply/last")
+		rebasedBranch(status, readGitFile(gitDir, "rebase-apply/head-name"))
+	case exists("MERGE_HEAD"):
+		status.Operation = "merge"
+	case exists("CHERRY_PICK_HEAD"):
+		status.Operation = "cherry-pick"
+	case exists("REVERT_HEAD"):
+		status.Operation = "revert"
+	case exists("BISECT_LOG"):
+		status.Operation = "bisect"
+	}
+}
 
-	dirty, err := gitCommandRunner(gitArgs(workspace, "status", "--porcelain")...)
-	if err == nil && strings.TrimSpace(dirty) != "" {
-		text += " *"
+// rebasedBranch shows the branch a rebase started from, if it was on one.
+func rebasedBranch(status *GitStatus, headName string) {
+	if branch, ok := strings.CutPrefix(headName, "refs/heads/"); ok {
+		status.Branch = branch
+		status.Detached = false
+	}
+}
+
+// readGitFile returns the trimmed contents of name in gitDir, or "".
+func readGitFile(gitDir, name string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// parsePorcelainV2 parses git status --porcelain=v2 --branch --show-stash.
+// The branch itself is read from the git directory instead.
+func parsePorcelainV2(out string) GitChanges {
+	var c GitChanges
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "#":
+			if len(fields) < 3 {
+				continue
+			}
+			switch fields[1] {
+			case "branch.upstream":
+				c.Upstream = fields[2]
+			case "branch.ab":
+				if len(fields) == 4 {
+					c.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+					c.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[3], "-"))
+				}
+			case "stash":
+				c.Stashes, _ = strconv.Atoi(fields[2])
+			}
+		case "1", "2":
+			// XY: index status then worktree status; "." means unchanged.
+			if len(fields) < 2 || len(fields[1]) != 2 {
+				continue
+			}
+			if fields[1][0] != '.' {
+				c.Staged++
+			}
+			if fields[1][1] != '.' {
+				c.Modified++
+			}
+		case "u":
+			c.Conflicted++
+		case "?":
+			c.Untracked++
+		}
+	}
+	return c
+}
+
+// shortSHA abbreviates a commit SHA the way git does by default, or returns
+// "HEAD" when it is unknown.
+func shortSHA(sha string) string {
+	if sha == "" {
+		return "HEAD"
+	}
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// repoPrefix names where the branch is checked out when it is not the main
+// working tree: "parent › path@" in a submodule, "name@" in a linked
+// worktree whose name differs from its branch.
+func repoPrefix(status *GitStatus) string {
+	switch {
+	case status.Superproject != "":
+		return status.Superproject + " › " + status.Submodule + "@"
+	case status.Worktree != "" && status.Worktree != status.Branch:
+		return status.Worktree + "@"
+	}
+	return ""
+}
+
+// operationLabels are the in-progress operation names shown in the git
+// segment, as git's prompt script shows them.
+var operationLabels = map[string]string{
+	"rebase":      "REBASE",
+	"am":          "AM",
+	"merge":       "MERGING",
+	"cherry-pick": "CHERRY-PICKING",
+	"revert":      "REVERTING",
+	"bisect":      "BISECTING",
+}
+
+// operationText formats the in-progress operation, e.g. "|REBASE 2/5".
+func operationText(status *GitStatus) string {
+	label, ok := operationLabels[status.Operation]
+	if !ok {
+		return ""
+	}
+	if status.Steps > 0 {
+		return fmt.Sprintf("|%s %d/%d", label, status.Step, status.Steps)
+	}
+	return "|" + label
+}
+
+// Git returns a segment displaying the current git branch followed by the
+// configured indicators, e.g. " main ↑2 ●1 ✚3". In a submodule or linked
+// worktree the branch is qualified, e.g. " app › lib@main" or
+// " review@main". An operation in progress is
+// appended to the branch, e.g. " main|REBASE 2/5", and switches the segment
+// to the warning colors. Returns a disabled segment when status is nil.
+func Git(status *GitStatus, opts GitOptions, theme themes.Theme) Segment {
+	if status == nil {
+		return Segment{Name: "git", Enabled: false}
+	}
+	colors := theme.Segments["git"]
+	if status.Operation != "" {
+		colors = theme.Segments["warning"]
+	}
+
+	spans := []Span{{Text: status.Branch}}
+	if !status.Detached {
+		spans = branchSpans(status.Branch, opts)
+	}
+	spans = joinSpans(BranchIcon+" "+repoPrefix(status), spans, operationText(status))
+	if status.ChangesUnknown && opts.UnknownMarker != "" {
+		spans = append(spans, Span{Text: " " + opts.UnknownMarker})
+	}
+	if status.Dirty() && opts.DirtyMarker != "" {
+		spans = append(spans, Span{Text: " " + opts.DirtyMarker})
+	}
+	for _, ind := range []struct {
+		count int
+		GitIndicator
+	}{
+		{status.Ahead, opts.Ahead},
+		{status.Behind, opts.Behind},
+		{status.Staged, opts.Staged},
+		{status.Modified, opts.Modified},
+		{status.Untracked, opts.Untracked},
+		{status.Conflicted, opts.Conflicted},
+		{status.Stashes, opts.Stash},
+	} {
+		if ind.count == 0 || ind.Icon == "" {
+			continue
+		}
+		spans = append(spans, Span{Text: " "}, Span{
+			Text: ind.Icon + strconv.Itoa(ind.count),
+			FG:   resolveColor(theme, ind.Color),
+		})
 	}
 
 	return Segment{
 		Name:    "git",
-		Text:    text,
+		Text:    spansText(spans),
+		Spans:   spans,
 		FG:      colors.FG,
 		BG:      colors.BG,
 		Enabled: true,
 	}
 }
 
+// joinSpans surrounds spans with plain before and after text, merged into
+// the neighbouring span when it carries no link.
+func joinSpans(before string, spans []Span, after string) []Span {
+	if spans[0].Link == "" {
+		spans[0].Text = before + spans[0].Text
+	} else {
+		spans = append([]Span{{Text: before}}, spans...)
+	}
+	if after == "" {
+		return spans
+	}
+	if last := &spans[len(spans)-1]; last.Link == "" {
+		last.Text += after
+		return spans
+	}
+	return append(spans, Span{Text: after})
+}
+
+// resolveColor maps a theme color key to that palette's background, which
+// stands out as text on the segment, and passes anything else, such as a
+// 256-color code, through unchanged.
+func resolveColor(theme themes.Theme, color string) string {
+	if c, ok := theme.Segments[color]; ok {
+		return c.BG
+	}
+	return color
+}
+
+// spansText concatenates the text of spans.
+func spansText(spans []Span) string {
+	var b strings.Builder
+	for _, s := range spans {
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
+
+// GitBranch returns the current branch name in workspace, "HEAD" when it is
+// detached, or "" when workspace is not a repository. It reads the git
+// directory directly rather than running git.
+func GitBranch(workspace string) string {
+	repo, ok := findRepo(workspace)
+	if !ok {
+		return ""
+	}
+	branch, _, ok := repo.head()
+	if !ok {
+		return ""
+	}
+	if branch == "" {
+		return "HEAD"
+	}
+	return branch
+}
+
+// GitRemoteURL returns the URL of the origin remote in workspace, or "" when
+// workspace is not a repository or has no origin. Like GitBranch, it reads
+// the repository's config file rather than running git.
+func GitRemoteURL(workspace string) string {
+	repo, ok := findRepo(workspace)
+	if !ok {
+		return ""
+	}
+	return repo.remoteURL("origin")
+}
+
 // gitArgs prepends -C <workspace> to git arguments when workspace is non-empty.
 func gitArgs(workspace string, args ...string) []string {
 	if workspace == "" {
@@ -50,8 +576,11 @@ func gitArgs(workspace string, args ...string) []string {
 	return append([]string{"-C", workspace}, args...)
 }
 
-func runGitCommand(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
+// runGitCommand runs git, killing it when ctx is done. Optional locks are
+// disabled so a background refresh of the index never makes the user's own
+// git commands fail on index.lock.
+func runGitCommand(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"--no-optional-locks"}, args...)...)
 	out, err := cmd.Output()
 	if err != nil {
 		return "", err
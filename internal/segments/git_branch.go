@@ -0,0 +1,72 @@
+package segments
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// issueKeyPlaceholder is replaced by the issue key in GitOptions.IssueURL.
+const issueKeyPlaceholder = "{{key}}"
+
+// branchSpans formats a branch for display: configured prefixes stripped,
+// the issue key, if any, moved to the front in its own span, and the rest
+// shortened to MaxBranchLength. When IssueURL is set, the key's span links
+// to the issue, so the link covers the key alone.
+//
+// With prefix "feature/" and pattern "[A-Z]+-[0-9]+",
+// "feature/PROJ-1234-short-desc" becomes "PROJ-1234 short-desc".
+func branchSpans(branch string, opts GitOptions) []Span {
+	for _, prefix := range opts.StripPrefixes {
+		if rest, ok := strings.CutPrefix(branch, prefix); ok && rest != "" {
+			branch = rest
+			break
+		}
+	}
+
+	key, rest := issueKey(branch, opts.IssuePattern)
+	if key == "" {
+		return []Span{{Text: truncateRunes(branch, opts.MaxBranchLength)}}
+	}
+	spans := []Span{{Text: key}}
+	if opts.IssueURL != "" {
+		spans[0].Link = strings.ReplaceAll(opts.IssueURL, issueKeyPlaceholder, url.PathEscape(key))
+	}
+	if rest != "" {
+		spans = append(spans, Span{Text: " " + truncateRunes(rest, opts.MaxBranchLength)})
+	}
+	return spans
+}
+
+// issueKey finds the issue key in branch and returns it with what is left of
+// the branch around it, trimmed of separators. An empty or invalid pattern,
+// or no match, yields no key.
+func issueKey(branch, pattern string) (key, rest string) {
+	if pattern == "" {
+		return "", branch
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", branch
+	}
+	m := re.FindStringSubmatchIndex(branch)
+	if m == nil {
+		return "", branch
+	}
+	start, end := m[0], m[1]
+	if len(m) >= 4 && m[2] >= 0 {
+		start, end = m[2], m[3]
+	}
+	const separators = "-_/. "
+	before := strings.Trim(branch[:m[0]], separators)
+	after := strings.Trim(branch[m[1]:], separators)
+	switch {
+	case before == "":
+		rest = after
+	case after == "":
+		rest = before
+	default:
+		rest = before + "/" + after
+	}
+	return branch[start:end], rest
+}
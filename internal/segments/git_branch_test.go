@@ -0,0 +1,111 @@
+package segments
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+)
+
+func TestBranchSpans(t *testing.T) {
+	jira := GitOptions{
+		StripPrefixes: []string{"feature/", "fix/"},
+		IssuePattern:  "[A-Z][A-Z0-9]+-[0-9]+",
+		IssueURL:      "https://jira.example.com/browse/{{key}}",
+	}
+	tests := []struct {
+		name     string
+		branch   string
+		opts     GitOptions
+		wantName string
+		wantLink string
+	}{
+		{name: "defaults leave the branch alone", branch: "feature/PROJ-1234-short-desc", opts: GitOptions{}, wantName: "feature/PROJ-1234-short-desc"},
+		{name: "key and description", branch: "feature/PROJ-1234-short-desc", opts: jira, wantName: "PROJ-1234 short-desc", wantLink: "https://jira.example.com/browse/PROJ-1234"},
+		{name: "key only", branch: "fix/OPS-7", opts: jira, wantName: "OPS-7", wantLink: "https://jira.example.com/browse/OPS-7"},
+		{name: "key in the middle", branch: "alice/PROJ-9_retry", opts: jira, wantName: "PROJ-9 alice/retry", wantLink: "https://jira.example.com/browse/PROJ-9"},
+		{name: "no key", branch: "feature/cleanup", opts: jira, wantName: "cleanup"},
+		{name: "prefix alone is kept", branch: "feature/", opts: jira, wantName: "feature/"},
+		{
+			name:     "capture group",
+			branch:   "gh-42-fix-login",
+			opts:     GitOptions{IssuePattern: "gh-([0-9]+)", IssueURL: "https://github.com/o/r/issues/{{key}}"},
+			wantName: "42 fix-login",
+			wantLink: "https://github.com/o/r/issues/42",
+		},
+		{
+			name:     "shortened description",
+			branch:   "feature/PROJ-1234-a-very-long-description",
+			opts:     GitOptions{StripPrefixes: jira.StripPrefixes, IssuePattern: jira.IssuePattern, MaxBranchLength: 8},
+			wantName: "PROJ-1234 a-very-…",
+		},
+		{name: "shortened branch without a key", branch: "experiment-with-things", opts: GitOptions{MaxBranchLength: 10}, wantName: "experimen…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := branchSpans(tt.branch, tt.opts)
+			if name, link := spansText(spans), spans[0].Link; name != tt.wantName || link != tt.wantLink {
+				t.Errorf("branchSpans(%q) = %q linked to %q; want %q linked to %q", tt.branch, name, link, tt.wantName, tt.wantLink)
+			}
+			for _, span := range spans[1:] {
+				if span.Link != "" {
+					t.Errorf("expected only the key linked, got %+v", spans)
+				}
+			}
+		})
+	}
+}
+
+func TestGitIssueLink(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	mockGitStatus(t, "")
+	repo := writeRepo(t, map[string]string{
+		"HEAD": "ref: refs/heads/feature/PROJ-1234-short-desc\n",
+		"refs/heads/feature/PROJ-1234-short-desc": headCommit + "\n",
+	})
+	opts := DefaultGitOptions()
+	opts.StripPrefixes = []string{"feature/"}
+	opts.IssuePattern = "[A-Z]+-[0-9]+"
+	opts.IssueURL = "https://jira.example.com/browse/{{key}}"
+
+	status := ReadGitStatus(context.Background(), "", repo)
+	status.Operation, status.Ahead = "merge", 2
+	seg := Git(status, opts, theme)
+
+	if seg.Text != "\ue0a0 PROJ-1234 short-desc|MERGING ↑2" {
+		t.Errorf("unexpected text %q", seg.Text)
+	}
+	// Only the key is a link, not the operation or the indicators.
+	var linked []string
+	for _, span := range seg.Spans {
+		if span.Link != "" {
+			linked = append(linked, span.Text+" -> "+span.Link)
+		}
+	}
+	if len(linked) != 1 || linked[0] != "PROJ-1234 -> https://jira.example.com/browse/PROJ-1234" || seg.Link != "" {
+		t.Errorf("expected the key alone linked, got %v and segment link %q", linked, seg.Link)
+	}
+
+	// A detached HEAD is a tag or SHA, not a branch to parse.
+	seg = Git(&GitStatus{Branch: "PROJ-1", Detached: true}, opts, theme)
+	for _, span := range seg.Spans {
+		if span.Link != "" {
+			t.Errorf("expected no link when detached, got %+v", seg.Spans)
+		}
+	}
+}
+
+func TestGitOptionsValidateIssue(t *testing.T) {
+	for _, opts := range []GitOptions{
+		{IssuePattern: "[A-Z+"},
+		{IssueURL: "https://jira.example.com/browse/"},
+		{MaxBranchLength: -1},
+	} {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+	if err := (GitOptions{IssuePattern: "[A-Z]+-[0-9]+", IssueURL: "https://x/{{key}}"}).Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
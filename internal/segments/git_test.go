@@ -1,29 +1,85 @@
 package segments
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rbarcante/conductor-powerline/internal/themes"
 )
 
-func TestGitCleanBranch(t *testing.T) {
-	theme, _ := themes.Get("dark")
-
-	// Mock git commands
+// mockGit answers each git subcommand with its entry in outputs, failing
+// the ones without an entry, and records every git call.
+func mockGit(t *testing.T, outputs map[string]string) *[][]string {
+	t.Helper()
 	origRunner := gitCommandRunner
-	defer func() { gitCommandRunner = origRunner }()
+	t.Cleanup(func() { gitCommandRunner = origRunner })
+
+	var calls [][]string
+	gitCommandRunner = func(_ context.Context, args ...string) (string, error) {
+		calls = append(calls, args)
+		sub := args
+		if len(sub) > 2 && sub[0] == "-C" {
+			sub = sub[2:]
+		}
+		if out, ok := outputs[sub[0]]; ok {
+			return out, nil
+		}
+		return "", &testError{msg: "unexpected git " + sub[0]}
+	}
+	return &calls
+}
+
+// mockGitStatus makes git status print out.
+func mockGitStatus(t *testing.T, out string) *[][]string {
+	t.Helper()
+	return mockGit(t, map[string]string{"status": out})
+}
+
+const headCommit = "1234567890abcdef1234567890abcdef12345678"
+
+// writeRepo creates a working tree whose .git holds files, on branch main
+// at headCommit unless files say otherwise, and returns its path.
+func writeRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	all := map[string]string{
+		"HEAD":            "ref: refs/heads/main\n",
+		"refs/heads/main": headCommit + "\n",
+	}
+	for name, content := range files {
+		all[name] = content
+	}
+	writeFiles(t, filepath.Join(dir, ".git"), all)
+	return dir
+}
 
-	gitCommandRunner = func(args ...string) (string, error) {
-		switch args[len(args)-1] {
-		case "HEAD":
-			return "main", nil
-		case "--porcelain":
-			return "", nil
+// writeFiles creates files under dir, with their parent directories.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
 		}
-		return "", nil
 	}
+}
 
-	seg := Git("", theme)
+const porcelainClean = `# branch.oid 1234567890abcdef1234567890abcdef12345678
+# branch.head main
+`
+
+func TestGitCleanBranch(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	mockGitStatus(t, porcelainClean)
+
+	seg := Git(ReadGitStatus(context.Background(), "", writeRepo(t, nil)), DefaultGitOptions(), theme)
 
 	if !seg.Enabled {
 		t.Error("expected segment enabled")
@@ -36,26 +92,189 @@ func TestGitCleanBranch(t *testing.T) {
 	}
 }
 
+const porcelainBusy = `# branch.oid 1234567890abcdef1234567890abcdef12345678
+# branch.head feature/my-branch
+# branch.upstream origin/feature/my-branch
+# branch.ab +2 -1
+# stash 3
+1 M. N... 100644 100644 100644 aaa bbb staged.go
+1 .M N... 100644 100644 100644 aaa bbb modified.go
+1 MM N... 100644 100644 100644 aaa bbb both.go
+2 R. N... 100644 100644 100644 aaa bbb R100 new.go	old.go
+u UU N... 100644 100644 100644 100644 aaa bbb ccc conflict.go
+? untracked.go
+? other.txt
+! ignored.log
+`
+
+// busyRepo is a repository on feature/my-branch, for porcelainBusy.
+func busyRepo(t *testing.T) string {
+	return writeRepo(t, map[string]string{
+		"HEAD":                         "ref: refs/heads/feature/my-branch\n",
+		"refs/heads/feature/my-branch": headCommit + "\n",
+	})
+}
+
+func TestParsePorcelainV2(t *testing.T) {
+	got := parsePorcelainV2(porcelainBusy)
+	want := GitChanges{
+		Upstream: "origin/feature/my-branch",
+		Ahead:    2, Behind: 1, Staged: 3, Modified: 2, Untracked: 2, Conflicted: 1, Stashes: 3,
+	}
+	if got != want {
+		t.Errorf("parsePorcelainV2 =\n%+v\nwant\n%+v", got, want)
+	}
+	if !got.Dirty() {
+		t.Error("expected dirty status")
+	}
+}
+
+func TestGitDetached(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	mockGitStatus(t, "")
+	repo := writeRepo(t, map[string]string{"HEAD": headCommit + "\n"})
+
+	status := ReadGitStatus(context.Background(), "", repo)
+	if !status.Detached {
+		t.Error("expected detached HEAD")
+	}
+	if seg := Git(status, DefaultGitOptions(), theme); seg.Text != "\ue0a0 1234567" {
+		t.Errorf("expected short SHA without a tag, got %q", seg.Text)
+	}
+
+	writeFiles(t, filepath.Join(repo, ".git"), map[string]string{"refs/tags/v1.2.0": headCommit + "\n"})
+	if seg := Git(ReadGitStatus(context.Background(), "", repo), DefaultGitOptions(), theme); seg.Text != "\ue0a0 v1.2.0" {
+		t.Errorf("expected exact tag, got %q", seg.Text)
+	}
+}
+
+func TestGitOperations(t *testing.T) {
+	detached := headCommit + "\n"
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name: "interactive rebase",
+			files: map[string]string{
+				"HEAD":                   detached,
+				"rebase-merge/head-name": "refs/heads/feature\n",
+				"rebase-merge/msgnum":    "2\n",
+				"rebase-merge/end":       "5\n",
+			},
+			want: "\ue0a0 feature|REBASE 2/5",
+		},
+		{
+			name: "rebase of a detached HEAD",
+			files: map[string]string{
+				"HEAD":                   detached,
+				"rebase-merge/head-name": "detached HEAD\n",
+				"rebase-merge/msgnum":    "1\n",
+				"rebase-merge/end":       "3\n",
+			},
+			want: "\ue0a0 1234567|REBASE 1/3",
+		},
+		{
+			name: "am",
+			files: map[string]string{
+				"rebase-apply/applying": "",
+				"rebase-apply/next":     "1\n",
+				"rebase-apply/last":     "4\n",
+			},
+			want: "\ue0a0 main|AM 1/4",
+		},
+		{name: "merge", files: map[string]string{"MERGE_HEAD": "abc\n"}, want: "\ue0a0 main|MERGING"},
+		{name: "cherry-pick", files: map[string]string{"CHERRY_PICK_HEAD": "abc\n"}, want: "\ue0a0 main|CHERRY-PICKING"},
+		{name: "revert", files: map[string]string{"REVERT_HEAD": "abc\n"}, want: "\ue0a0 main|REVERTING"},
+		{name: "bisect", files: map[string]string{"HEAD": detached, "BISECT_LOG": "# bad: abc\n"}, want: "\ue0a0 1234567|BISECTING"},
+	}
+	theme, _ := themes.Get("dark")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGitStatus(t, "")
+
+			seg := Git(ReadGitStatus(context.Background(), "", writeRepo(t, tt.files)), DefaultGitOptions(), theme)
+
+			if seg.Text != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, seg.Text)
+			}
+			if seg.BG != theme.Segments["warning"].BG {
+				t.Errorf("expected warning colors during an operation, got BG %s", seg.BG)
+			}
+		})
+	}
+}
+
+func TestGitNoOperation(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	mockGitStatus(t, porcelainClean)
+
+	seg := Git(ReadGitStatus(context.Background(), "", writeRepo(t, nil)), DefaultGitOptions(), theme)
+
+	if seg.Text != "\ue0a0 main" || seg.BG != theme.Segments["git"].BG {
+		t.Errorf("expected plain git segment, got %q with BG %s", seg.Text, seg.BG)
+	}
+}
+
 func TestGitDirtyBranch(t *testing.T) {
 	theme, _ := themes.Get("dark")
+	mockGitStatus(t, porcelainBusy)
 
-	origRunner := gitCommandRunner
-	defer func() { gitCommandRunner = origRunner }()
+	seg := Git(ReadGitStatus(context.Background(), "", busyRepo(t)), DefaultGitOptions(), theme)
 
-	gitCommandRunner = func(args ...string) (string, error) {
-		switch args[len(args)-1] {
-		case "HEAD":
-			return "feature/my-branch", nil
-		case "--porcelain":
-			return " M file.go\n", nil
+	if want := "\ue0a0 feature/my-branch ↑2 ↓1 ●3 ✚2 …2 ✖1 ⚑3"; seg.Text != want {
+		t.Errorf("expected %q, got %q", want, seg.Text)
+	}
+	var conflict Span
+	for _, span := range seg.Spans {
+		if span.Text == "✖1" {
+			conflict = span
 		}
-		return "", nil
 	}
+	if conflict.FG != theme.Segments["critical"].BG {
+		t.Errorf("expected conflicts in the critical color, got %+v", conflict)
+	}
+}
+
+func TestGitIndicatorOptions(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	mockGitStatus(t, porcelainBusy)
 
-	seg := Git("", theme)
+	opts := GitOptions{Modified: GitIndicator{Icon: "!", Color: "208"}, Stash: GitIndicator{Icon: "$"}}
+	seg := Git(ReadGitStatus(context.Background(), "", busyRepo(t)), opts, theme)
 
-	if seg.Text != "\ue0a0 feature/my-branch *" {
-		t.Errorf("expected dirty indicator, got %q", seg.Text)
+	if want := "\ue0a0 feature/my-branch !2 $3"; seg.Text != want {
+		t.Errorf("expected only configured indicators %q, got %q", want, seg.Text)
+	}
+	if seg.Spans[2].FG != "208" {
+		t.Errorf("expected raw color code passed through, got %+v", seg.Spans[2])
+	}
+}
+
+func TestGitOptionsValidate(t *testing.T) {
+	for _, color := range []string{"", "warning", "0", "208"} {
+		if err := (GitOptions{Ahead: GitIndicator{Color: color}}).Validate(); err != nil {
+			t.Errorf("color %q: unexpected error %v", color, err)
+		}
+	}
+	for _, color := range []string{"256", "-1", "orange"} {
+		if err := (GitOptions{Stash: GitIndicator{Color: color}}).Validate(); err == nil {
+			t.Errorf("color %q: expected error", color)
+		}
+	}
+}
+
+func TestGitDirtyMarkerOption(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	mockGitStatus(t, porcelainClean+"1 .M N... 100644 100644 100644 aaa bbb file.go\n")
+	repo := writeRepo(t, nil)
+
+	if seg := Git(ReadGitStatus(context.Background(), "", repo), GitOptions{DirtyMarker: "±"}, theme); seg.Text != "\ue0a0 main ±" {
+		t.Errorf("expected custom marker, got %q", seg.Text)
+	}
+	if seg := Git(ReadGitStatus(context.Background(), "", repo), GitOptions{}, theme); seg.Text != "\ue0a0 main" {
+		t.Errorf("expected empty marker to hide dirty state, got %q", seg.Text)
 	}
 }
 
@@ -65,53 +284,76 @@ func TestGitUnavailable(t *testing.T) {
 	origRunner := gitCommandRunner
 	defer func() { gitCommandRunner = origRunner }()
 
-	gitCommandRunner = func(args ...string) (string, error) {
+	gitCommandRunner = func(_ context.Context, args ...string) (string, error) {
 		return "", &testError{msg: "git not found"}
 	}
 
-	seg := Git("", theme)
+	seg := Git(ReadGitStatus(context.Background(), "", writeRepo(t, nil)), DefaultGitOptions(), theme)
 
-	if seg.Enabled {
-		t.Error("expected segment disabled when git unavailable")
+	// The branch is read without git; only the changes are missing.
+	if !seg.Enabled || seg.Text != "\ue0a0 main" {
+		t.Errorf("expected branch without changes when git is unavailable, got %+v", seg)
 	}
 }
 
-func TestGitNotARepo(t *testing.T) {
+func TestGitStatusTimeout(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
 	origRunner := gitCommandRunner
 	defer func() { gitCommandRunner = origRunner }()
 
-	gitCommandRunner = func(args ...string) (string, error) {
-		return "", &testError{msg: "not a git repository"}
+	gitCommandRunner = func(ctx context.Context, args ...string) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
 	}
 
-	seg := Git("", theme)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	cacheDir := t.TempDir()
+	repo := writeRepo(t, nil)
+	status := ReadGitStatus(ctx, cacheDir, repo)
+
+	if !status.ChangesUnknown {
+		t.Fatal("expected changes unknown after a timeout")
+	}
+	if seg := Git(status, DefaultGitOptions(), theme); seg.Text != "\ue0a0 main ?" {
+		t.Errorf("expected unknown marker, got %q", seg.Text)
+	}
+	if seg := Git(status, GitOptions{DirtyMarker: "*"}, theme); seg.Text != "\ue0a0 main" {
+		t.Errorf("expected empty unknown marker to hide it, got %q", seg.Text)
+	}
+
+	// The timeout is not cached as a clean tree.
+	mockGitStatus(t, porcelainBusy)
+	if status := ReadGitStatus(context.Background(), cacheDir, repo); status.ChangesUnknown || !status.Dirty() {
+		t.Errorf("expected a fresh git status after a timeout, got %+v", status)
+	}
+}
+
+func TestGitNotARepo(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	calls := mockGitStatus(t, porcelainClean)
+
+	seg := Git(ReadGitStatus(context.Background(), "", t.TempDir()), DefaultGitOptions(), theme)
 
 	if seg.Enabled {
 		t.Error("expected segment disabled when not in a git repo")
 	}
+	if len(*calls) != 0 {
+		t.Errorf("expected git not to run outside a repo, got %v", *calls)
+	}
 }
 
 func TestGitWithWorkspacePath(t *testing.T) {
 	theme, _ := themes.Get("dark")
-
-	origRunner := gitCommandRunner
-	defer func() { gitCommandRunner = origRunner }()
-
-	var capturedArgs []string
-	gitCommandRunner = func(args ...string) (string, error) {
-		capturedArgs = append(capturedArgs, args...)
-		switch args[len(args)-1] {
-		case "HEAD":
-			return "develop", nil
-		case "--porcelain":
-			return "", nil
-		}
-		return "", nil
+	calls := mockGitStatus(t, porcelainClean)
+	repo := writeRepo(t, map[string]string{"HEAD": "ref: refs/heads/develop\n"})
+	workspace := filepath.Join(repo, "services", "api")
+	if err := os.MkdirAll(workspace, 0o755); err != nil {
+		t.Fatal(err)
 	}
 
-	seg := Git("/home/user/my-project", theme)
+	seg := Git(ReadGitStatus(context.Background(), "", workspace), DefaultGitOptions(), theme)
 
 	if !seg.Enabled {
 		t.Error("expected segment enabled with workspace path")
@@ -120,45 +362,29 @@ func TestGitWithWorkspacePath(t *testing.T) {
 		t.Errorf("expected branch 'develop', got %q", seg.Text)
 	}
 
-	// Verify -C flag was passed for both commands
-	foundC := false
-	for i, arg := range capturedArgs {
-		if arg == "-C" && i+1 < len(capturedArgs) && capturedArgs[i+1] == "/home/user/my-project" {
-			foundC = true
-			break
-		}
+	// A single git status call, targeted with -C
+	if len(*calls) != 1 {
+		t.Fatalf("expected one git call, got %v", *calls)
 	}
-	if !foundC {
-		t.Errorf("expected -C /home/user/my-project in git args, got %v", capturedArgs)
+	want := "-C " + workspace + " status --porcelain=v2 --branch --show-stash"
+	if got := strings.Join((*calls)[0], " "); got != want {
+		t.Errorf("expected %q, got %q", want, got)
 	}
 }
 
 func TestGitWorkspacePathIgnoredWhenEmpty(t *testing.T) {
 	theme, _ := themes.Get("dark")
+	calls := mockGitStatus(t, porcelainClean)
+	t.Chdir(writeRepo(t, nil))
 
-	origRunner := gitCommandRunner
-	defer func() { gitCommandRunner = origRunner }()
-
-	var capturedArgs []string
-	gitCommandRunner = func(args ...string) (string, error) {
-		capturedArgs = append(capturedArgs, args...)
-		switch args[len(args)-1] {
-		case "HEAD":
-			return "main", nil
-		case "--porcelain":
-			return "", nil
-		}
-		return "", nil
-	}
-
-	seg := Git("", theme)
+	seg := Git(ReadGitStatus(context.Background(), "", ""), DefaultGitOptions(), theme)
 
 	if !seg.Enabled {
 		t.Error("expected segment enabled")
 	}
 
 	// Verify -C flag was NOT passed
-	for _, arg := range capturedArgs {
+	for _, arg := range (*calls)[0] {
 		if arg == "-C" {
 			t.Error("expected no -C flag when workspace is empty")
 			break
@@ -166,6 +392,74 @@ func TestGitWorkspacePathIgnoredWhenEmpty(t *testing.T) {
 	}
 }
 
+func TestGitChangesCache(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	calls := mockGitStatus(t, porcelainBusy)
+	repo := busyRepo(t)
+	cacheDir := t.TempDir()
+	index := filepath.Join(repo, ".git", "index")
+	writeFiles(t, filepath.Join(repo, ".git"), map[string]string{"index": "v1"})
+
+	first := Git(ReadGitStatus(context.Background(), cacheDir, repo), DefaultGitOptions(), theme)
+	second := Git(ReadGitStatus(context.Background(), cacheDir, repo), DefaultGitOptions(), theme)
+	if len(*calls) != 1 {
+		t.Fatalf("expected git status to run once for an unchanged index, ran %d times", len(*calls))
+	}
+	if first.Text != second.Text {
+		t.Errorf("cached render %q differs from %q", second.Text, first.Text)
+	}
+
+	// Staging a file rewrites the index.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(index, later, later); err != nil {
+		t.Fatal(err)
+	}
+	ReadGitStatus(context.Background(), cacheDir, repo)
+	if len(*calls) != 2 {
+		t.Errorf("expected git status to run again after the index changed, ran %d times", len(*calls))
+	}
+
+	// A commit moves HEAD.
+	writeFiles(t, filepath.Join(repo, ".git"), map[string]string{"refs/heads/feature/my-branch": "abcdef\n"})
+	if err := os.Chtimes(index, later, later); err != nil {
+		t.Fatal(err)
+	}
+	ReadGitStatus(context.Background(), cacheDir, repo)
+	if len(*calls) != 3 {
+		t.Errorf("expected git status to run again after HEAD moved, ran %d times", len(*calls))
+	}
+}
+
+func TestGitBranchAndRemoteURL(t *testing.T) {
+	calls := mockGit(t, nil)
+	repo := writeRepo(t, map[string]string{
+		"HEAD":   "ref: refs/heads/feature/x\n",
+		"config": "[remote \"origin\"]\n\turl = git@github.com:owner/repo.git\n",
+	})
+	if got := GitBranch(repo); got != "feature/x" {
+		t.Errorf("GitBranch = %q, want feature/x", got)
+	}
+	if got := GitRemoteURL(repo); got != "git@github.com:owner/repo.git" {
+		t.Errorf("GitRemoteURL = %q", got)
+	}
+	if got := GitBranch(writeRepo(t, map[string]string{"HEAD": headCommit})); got != "HEAD" {
+		t.Errorf("expected HEAD when detached, got %q", got)
+	}
+	if got := GitRemoteURL(writeRepo(t, nil)); got != "" {
+		t.Errorf("expected empty remote without an origin, got %q", got)
+	}
+
+	if got := GitBranch(t.TempDir()); got != "" {
+		t.Errorf("expected empty branch outside a repo, got %q", got)
+	}
+	if got := GitRemoteURL(t.TempDir()); got != "" {
+		t.Errorf("expected empty remote outside a repo, got %q", got)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("expected no git commands, ran %v", *calls)
+	}
+}
+
 // testError implements the error interface for testing.
 type testError struct {
 	msg string
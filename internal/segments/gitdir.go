@@ -0,0 +1,334 @@
+package segments
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxSymrefDepth bounds how many symbolic refs are followed, as git does.
+const maxSymrefDepth = 5
+
+// gitRepo locates a repository's directories, read without running git.
+type gitRepo struct {
+	// root is the top of the working tree, where .git was found.
+	root string
+	// gitDir holds HEAD, the index and in-progress operation state. For a
+	// linked worktree or submodule it is not inside the working tree.
+	gitDir string
+	// commonDir holds refs and objects shared by all worktrees.
+	commonDir string
+}
+
+// findRepo walks up from dir to the nearest .git, which is either the git
+// directory itself or a file pointing at it ("gitdir: <path>"), as used by
+// linked worktrees and submodules.
+func findRepo(dir string) (*gitRepo, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, false
+	}
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			gitDir := dotGit
+			if !info.IsDir() {
+				if gitDir = readGitdirFile(dotGit); gitDir == "" {
+					return nil, false
+				}
+			}
+			repo := newGitRepo(gitDir)
+			repo.root = dir
+			return repo, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, false
+		}
+		dir = parent
+	}
+}
+
+// readGitdirFile resolves the "gitdir: <path>" line of a .git file, relative
+// to the file's directory. Returns "" if it is malformed.
+func readGitdirFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	return filepath.Clean(target)
+}
+
+// newGitRepo resolves the common directory named by gitDir/commondir, which
+// only linked worktrees have.
+func newGitRepo(gitDir string) *gitRepo {
+	repo := &gitRepo{gitDir: gitDir, commonDir: gitDir}
+	if common := readGitFile(gitDir, "commondir"); common != "" {
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		repo.commonDir = filepath.Clean(common)
+	}
+	return repo
+}
+
+// worktreeName returns the name of a linked worktree, which git gives its
+// directory under the common directory's worktrees/, or "" for the main one.
+func (r *gitRepo) worktreeName() string {
+	if r.gitDir == r.commonDir {
+		return ""
+	}
+	return filepath.Base(r.gitDir)
+}
+
+// superproject returns the repository r is a submodule of, and the
+// submodule's path within it. ok is false when r is not a submodule, which
+// includes a repository merely cloned inside another one.
+func (r *gitRepo) superproject() (parent *gitRepo, path string, ok bool) {
+	parent, ok = findRepo(filepath.Dir(r.root))
+	if !ok {
+		return nil, "", false
+	}
+	rel, err := filepath.Rel(parent.root, r.root)
+	if err != nil {
+		return nil, "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if !parent.hasSubmodule(rel) {
+		return nil, "", false
+	}
+	return parent, rel, true
+}
+
+// hasSubmodule reports whether .gitmodules at the top of r's working tree
+// declares a submodule at path.
+func (r *gitRepo) hasSubmodule(path string) bool {
+	f, err := os.Open(filepath.Join(r.root, ".gitmodules"))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && strings.TrimSpace(key) == "path" && strings.Trim(strings.TrimSpace(value), `"`) == path {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteURL returns the url of the named remote from the repository's
+// config file, or "" if it has none. Only the plain config file is read:
+// includes and url.<base>.insteadOf rewrites are not applied.
+func (r *gitRepo) remoteURL(name string) string {
+	f, err := os.Open(filepath.Join(r.commonDir, "config"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	subsection := `"` + name + `"`
+	inSection := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[':
+			header, _, _ := strings.Cut(line[1:], "]")
+			// Section names are case-insensitive, subsection names are not.
+			kind, sub, _ := strings.Cut(header, " ")
+			inSection = strings.EqualFold(kind, "remote") && strings.TrimSpace(sub) == subsection
+		case inSection:
+			key, value, ok := strings.Cut(line, "=")
+			if ok && strings.EqualFold(strings.TrimSpace(key), "url") {
+				return strings.Trim(strings.TrimSpace(value), `"`)
+			}
+		}
+	}
+	return ""
+}
+
+// remoteDefaultBranch returns the branch the named remote's HEAD points at,
+// as recorded by clone or "git remote set-head", or "" if unknown.
+func (r *gitRepo) remoteDefaultBranch(name string) string {
+	prefix := "refs/remotes/" + name + "/"
+	target, ok := strings.CutPrefix(r.readLooseRef(prefix+"HEAD"), "ref:")
+	if !ok {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(target), prefix)
+}
+
+// head reads HEAD. On a branch it returns the branch name and the commit it
+// points at, which is "" for a branch with no commits yet. Detached, branch
+// is "" and commit is the checked out commit. ok is false when HEAD cannot
+// be read.
+func (r *gitRepo) head() (branch, commit string, ok bool) {
+	ref := readGitFile(r.gitDir, "HEAD")
+	if ref == "" {
+		return "", "", false
+	}
+	target, symbolic := strings.CutPrefix(ref, "ref:")
+	if !symbolic {
+		return "", ref, true
+	}
+	target = strings.TrimSpace(target)
+	return strings.TrimPrefix(target, "refs/heads/"), r.resolveRef(target), true
+}
+
+// resolveRef returns the commit a ref points at, following symbolic refs and
+// falling back to packed-refs. Returns "" for a ref that does not exist.
+func (r *gitRepo) resolveRef(name string) string {
+	for range maxSymrefDepth {
+		value := r.readLooseRef(name)
+		if value == "" {
+			return r.packedRefs()[name].commit
+		}
+		target, symbolic := strings.CutPrefix(value, "ref:")
+		if !symbolic {
+			return value
+		}
+		name = strings.TrimSpace(target)
+	}
+	return ""
+}
+
+// readLooseRef reads a ref file. Refs under refs/ other than per-worktree
+// ones live in the common directory; pseudo-refs live in the git directory.
+func (r *gitRepo) readLooseRef(name string) string {
+	if strings.HasPrefix(name, "refs/") && !strings.HasPrefix(name, "refs/bisect/") &&
+		!strings.HasPrefix(name, "refs/worktree/") && !strings.HasPrefix(name, "refs/rewritten/") {
+		return readGitFile(r.commonDir, name)
+	}
+	return readGitFile(r.gitDir, name)
+}
+
+// packedRef is one packed-refs entry: the object the ref names and, for an
+// annotated tag, the commit it peels to.
+type packedRef struct {
+	commit string
+	peeled string
+}
+
+// packedRefs parses the common directory's packed-refs file.
+func (r *gitRepo) packedRefs() map[string]packedRef {
+	refs := map[string]packedRef{}
+	f, err := os.Open(filepath.Join(r.commonDir, "packed-refs"))
+	if err != nil {
+		return refs
+	}
+	defer f.Close()
+
+	var last string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "" || line[0] == '#':
+		case line[0] == '^':
+			if ref, ok := refs[last]; ok {
+				ref.peeled = line[1:]
+				refs[last] = ref
+			}
+		default:
+			commit, name, ok := strings.Cut(line, " ")
+			if !ok {
+				continue
+			}
+			refs[name] = packedRef{commit: commit}
+			last = name
+		}
+	}
+	return refs
+}
+
+// exactTag returns the name of a tag pointing at commit, or "" if there is
+// none. Loose tags shadow packed ones of the same name, and annotated tags
+// are peeled to their commit.
+func (r *gitRepo) exactTag(commit string) string {
+	if commit == "" {
+		return ""
+	}
+	var found string
+	tagsDir := filepath.Join(r.commonDir, "refs", "tags")
+	loose := map[string]bool{}
+	_ = filepath.WalkDir(tagsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(tagsDir, path)
+		if err != nil {
+			return nil
+		}
+		name := filepath.ToSlash(rel)
+		loose[name] = true
+		if found == "" {
+			if id := readGitFile(tagsDir, rel); id == commit || r.peelTag(id) == commit {
+				found = name
+			}
+		}
+		return nil
+	})
+	if found != "" {
+		return found
+	}
+
+	for name, ref := range r.packedRefs() {
+		tag, ok := strings.CutPrefix(name, "refs/tags/")
+		if !ok || loose[tag] {
+			continue
+		}
+		if (ref.commit == commit || ref.peeled == commit) && (found == "" || tag < found) {
+			found = tag
+		}
+	}
+	return found
+}
+
+// peelTag returns the object an annotated tag points at, read from its loose
+// object. Returns "" if id is not a loose tag object.
+func (r *gitRepo) peelTag(id string) string {
+	if len(id) < 3 {
+		return ""
+	}
+	f, err := os.Open(filepath.Join(r.commonDir, "objects", id[:2], id[2:]))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return ""
+	}
+	defer zr.Close()
+
+	// "tag <size>\x00object <id>\n..." — the header is all that is needed.
+	buf := make([]byte, 128)
+	n, _ := io.ReadFull(zr, buf)
+	header, body, ok := bytes.Cut(buf[:n], []byte{0})
+	if !ok || !bytes.HasPrefix(header, []byte("tag ")) {
+		return ""
+	}
+	line, _, _ := bytes.Cut(body, []byte("\n"))
+	object, ok := bytes.CutPrefix(line, []byte("object "))
+	if !ok {
+		return ""
+	}
+	return string(object)
+}
@@ -0,0 +1,197 @@
+package segments
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+)
+
+func TestFindRepoFromSubdirectory(t *testing.T) {
+	repo := writeRepo(t, nil)
+	sub := filepath.Join(repo, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	r, ok := findRepo(sub)
+	if !ok || r.gitDir != filepath.Join(repo, ".git") || r.commonDir != r.gitDir {
+		t.Fatalf("findRepo = %+v, %v", r, ok)
+	}
+	if _, ok := findRepo(t.TempDir()); ok {
+		t.Error("expected no repo outside a working tree")
+	}
+}
+
+func TestLinkedWorktree(t *testing.T) {
+	main := writeRepo(t, map[string]string{
+		"refs/heads/feature":      "abcdef0123\n",
+		"worktrees/wt/HEAD":       "ref: refs/heads/feature\n",
+		"worktrees/wt/commondir":  "../..\n",
+		"worktrees/wt/MERGE_HEAD": "abc\n",
+	})
+
+	wt := t.TempDir()
+	gitdir := filepath.Join(main, ".git", "worktrees", "wt")
+	writeFiles(t, wt, map[string]string{".git": "gitdir: " + gitdir + "\n"})
+
+	r, ok := findRepo(wt)
+	if !ok {
+		t.Fatal("expected the worktree to be found")
+	}
+	if r.gitDir != gitdir || r.commonDir != filepath.Join(main, ".git") {
+		t.Errorf("unexpected dirs %+v", r)
+	}
+	if branch, commit, _ := r.head(); branch != "feature" || commit != "abcdef0123" {
+		t.Errorf("head = %q %q, want feature at abcdef0123", branch, commit)
+	}
+
+	mockGitStatus(t, "")
+	status := ReadGitStatus(context.Background(), "", wt)
+	if status.Operation != "merge" {
+		t.Errorf("expected the worktree's own merge, got %+v", status)
+	}
+	theme, _ := themes.Get("dark")
+	if seg := Git(status, DefaultGitOptions(), theme); seg.Text != "\ue0a0 wt@feature|MERGING" {
+		t.Errorf("expected the worktree name with the branch, got %q", seg.Text)
+	}
+
+	// A worktree named after its branch is not repeated.
+	status.Worktree = "feature"
+	if seg := Git(status, DefaultGitOptions(), theme); seg.Text != "\ue0a0 feature|MERGING" {
+		t.Errorf("expected the branch alone, got %q", seg.Text)
+	}
+	if status := ReadGitStatus(context.Background(), "", main); status.Worktree != "" {
+		t.Errorf("expected no worktree name in the main working tree, got %q", status.Worktree)
+	}
+}
+
+func TestSubmoduleGitdirFile(t *testing.T) {
+	parent := writeRepo(t, map[string]string{
+		"modules/lib/HEAD":            "ref: refs/heads/dev\n",
+		"modules/lib/refs/heads/dev":  headCommit + "\n",
+		"modules/lib/refs/heads/main": "ffff\n",
+	})
+	writeFiles(t, parent, map[string]string{"lib/.git": "gitdir: ../.git/modules/lib\n"})
+
+	if got := GitBranch(filepath.Join(parent, "lib")); got != "dev" {
+		t.Errorf("GitBranch = %q, want dev", got)
+	}
+}
+
+func TestGitSubmodule(t *testing.T) {
+	mockGitStatus(t, "")
+	theme, _ := themes.Get("dark")
+	parent := writeRepo(t, map[string]string{
+		"modules/vendor/lib/HEAD":           "ref: refs/heads/dev\n",
+		"modules/vendor/lib/refs/heads/dev": headCommit + "\n",
+	})
+	writeFiles(t, parent, map[string]string{
+		".gitmodules":     "[submodule \"lib\"]\n\tpath = vendor/lib\n\turl = https://example.com/lib.git\n",
+		"vendor/lib/.git": "gitdir: ../../.git/modules/vendor/lib\n",
+	})
+	workspace := filepath.Join(parent, "vendor", "lib", "src")
+	if err := os.MkdirAll(workspace, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	seg := Git(ReadGitStatus(context.Background(), "", workspace), DefaultGitOptions(), theme)
+
+	if want := "\ue0a0 " + filepath.Base(parent) + " › vendor/lib@dev"; seg.Text != want {
+		t.Errorf("expected %q, got %q", want, seg.Text)
+	}
+
+	// A repository cloned inside another is not a submodule.
+	nested := filepath.Join(parent, "scratch")
+	writeFiles(t, filepath.Join(nested, ".git"), map[string]string{"HEAD": "ref: refs/heads/main\n"})
+	if status := ReadGitStatus(context.Background(), "", nested); status.Superproject != "" {
+		t.Errorf("expected no superproject for a nested clone, got %+v", status)
+	}
+}
+
+func TestResolveRefPackedAndSymbolic(t *testing.T) {
+	repo := writeRepo(t, map[string]string{
+		"HEAD":                     "ref: refs/heads/alias\n",
+		"refs/heads/alias":         "ref: refs/heads/packed\n",
+		"refs/remotes/origin/HEAD": "ref: refs/remotes/origin/main\n",
+		"packed-refs":              "# pack-refs with: peeled fully-peeled sorted\n" + headCommit + " refs/heads/packed\n" + "aaaa refs/remotes/origin/main\n",
+	})
+	r, _ := findRepo(repo)
+
+	if branch, commit, _ := r.head(); branch != "alias" || commit != headCommit {
+		t.Errorf("head = %q %q, want alias at the packed commit", branch, commit)
+	}
+	if got := r.resolveRef("refs/remotes/origin/HEAD"); got != "aaaa" {
+		t.Errorf("resolveRef(origin/HEAD) = %q", got)
+	}
+	if got := r.resolveRef("refs/heads/missing"); got != "" {
+		t.Errorf("expected missing ref to resolve to nothing, got %q", got)
+	}
+
+	writeFiles(t, filepath.Join(repo, ".git"), map[string]string{"refs/heads/a": "ref: refs/heads/b\n", "refs/heads/b": "ref: refs/heads/a\n"})
+	if got := r.resolveRef("refs/heads/a"); got != "" {
+		t.Errorf("expected a symref loop to resolve to nothing, got %q", got)
+	}
+}
+
+// writeLooseTag stores an annotated tag object pointing at commit and
+// returns its id.
+func writeLooseTag(t *testing.T, gitDir, id, commit string) string {
+	t.Helper()
+	body := fmt.Sprintf("object %s\ntype commit\ntag v2\ntagger A <a@b> 0 +0000\n\nrelease\n", commit)
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	fmt.Fprintf(zw, "tag %d\x00%s", len(body), body)
+	zw.Close()
+	writeFiles(t, gitDir, map[string]string{filepath.Join("objects", id[:2], id[2:]): buf.String()})
+	return id
+}
+
+func TestExactTag(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{name: "none", files: nil, want: ""},
+		{name: "lightweight loose", files: map[string]string{"refs/tags/release/v1": headCommit + "\n"}, want: "release/v1"},
+		{
+			name:  "packed and peeled",
+			files: map[string]string{"packed-refs": "cccc refs/tags/v0\n^" + headCommit + "\n" + headCommit + " refs/tags/v1\n"},
+			want:  "v0",
+		},
+		{
+			name: "loose shadows packed",
+			files: map[string]string{
+				"packed-refs":  headCommit + " refs/tags/v1\n",
+				"refs/tags/v1": "dddd\n",
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := findRepo(writeRepo(t, tt.files))
+			if got := r.exactTag(headCommit); got != tt.want {
+				t.Errorf("exactTag = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("annotated loose", func(t *testing.T) {
+		repo := writeRepo(t, nil)
+		gitDir := filepath.Join(repo, ".git")
+		id := writeLooseTag(t, gitDir, "abcdef0123456789abcdef0123456789abcdef01", headCommit)
+		writeFiles(t, gitDir, map[string]string{"refs/tags/v2": id + "\n"})
+
+		r, _ := findRepo(repo)
+		if got := r.exactTag(headCommit); got != "v2" {
+			t.Errorf("exactTag = %q, want v2", got)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package segments
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/rbarcante/conductor-powerline/internal/config"
+	"github.com/rbarcante/conductor-powerline/internal/hook"
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+)
+
+// HookFieldOptions configures a custom segment of type hook_field, which
+// shows one value from the hook payload so new Claude Code fields can be
+// displayed without a dedicated segment.
+type HookFieldOptions struct {
+	// Pointer is the JSON pointer to the value, e.g. "/output_style/name".
+	Pointer string `json:"pointer"`
+	// Format is the printf format for the value, e.g. "v%s" or "%.1f%%".
+	Format string `json:"format"`
+	// Icon is shown before the value.
+	Icon string `json:"icon"`
+	// Thresholds colors a numeric value like an entry of the top-level
+	// thresholds, with warning, critical or levels. Empty disables it.
+	Thresholds config.ThresholdConfig `json:"thresholds"`
+}
+
+// DefaultHookFieldOptions returns the hook_field defaults. Pointer has no
+// default and must be set.
+func DefaultHookFieldOptions() HookFieldOptions {
+	return HookFieldOptions{Format: "%v"}
+}
+
+// Validate implements Options.
+func (o HookFieldOptions) Validate() error {
+	if !strings.HasPrefix(o.Pointer, "/") {
+		return fmt.Errorf("pointer must be a JSON pointer starting with \"/\", got %q", o.Pointer)
+	}
+	if !strings.Contains(o.Format, "%") {
+		return fmt.Errorf("format must contain a verb such as %%v, got %q", o.Format)
+	}
+	if t := o.Thresholds; t.Warning < 0 || t.Critical < 0 {
+		return errors.New("thresholds.warning and thresholds.critical must not be negative")
+	}
+	for i, l := range o.Thresholds.Levels {
+		if l.Color == "" {
+			return fmt.Errorf("thresholds.levels[%d].color must be a theme color key", i)
+		}
+	}
+	return nil
+}
+
+// HookField returns the custom segment name showing the payload value at
+// opts.Pointer. Returns a disabled segment when the payload has no value
+// there or opts has no pointer.
+func HookField(name string, data hook.Data, opts HookFieldOptions, theme themes.Theme) Segment {
+	if opts.Pointer == "" {
+		return Segment{Name: name, Enabled: false}
+	}
+	value, ok := data.Field(opts.Pointer)
+	if !ok || value == nil {
+		return Segment{Name: name, Enabled: false}
+	}
+
+	colors := theme.Segments["hook_field"]
+	if n, isNumber := value.(float64); isNumber {
+		colors = thresholdColors(theme, ThresholdsFrom(opts.Thresholds), n, "hook_field")
+	}
+
+	text := formatHookValue(opts.Format, value)
+	if opts.Icon != "" {
+		text = opts.Icon + " " + text
+	}
+
+	return Segment{
+		Name:    name,
+		Text:    text,
+		FG:      colors.FG,
+		BG:      colors.BG,
+		Enabled: true,
+	}
+}
+
+// formatHookValue applies format to a decoded JSON value. Whole numbers are
+// tried as integers first so "%d" and "%v" print 3 rather than 3.0 or 3e+00;
+// objects and arrays are shown as compact JSON. A format that does not suit
+// the value's type falls back to its plain form.
+func formatHookValue(format string, value any) string {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			if out := fmt.Sprintf(format, int64(v)); !strings.Contains(out, "%!") {
+				return out
+			}
+		}
+	case map[string]any, []any:
+		b, _ := json.Marshal(v)
+		value = string(b)
+	}
+	out := fmt.Sprintf(format, value)
+	if strings.Contains(out, "%!") {
+		return fmt.Sprint(value)
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package segments
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rbarcante/conductor-powerline/internal/config"
+	"github.com/rbarcante/conductor-powerline/internal/hook"
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+)
+
+func hookData(t *testing.T, input string) hook.Data {
+	t.Helper()
+	data, err := hook.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestHookField(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	data := hookData(t, `{"version": "2.1.0", "output_style": {"name": "Explanatory"}, "n": 3, "cost": {"total_cost_usd": 0.4231}, "dirs": ["/a"]}`)
+
+	tests := []struct {
+		opts HookFieldOptions
+		want string
+	}{
+		{HookFieldOptions{Pointer: "/version", Format: "v%s"}, "v2.1.0"},
+		{HookFieldOptions{Pointer: "/output_style/name", Format: "%v", Icon: "✎"}, "✎ Explanatory"},
+		{HookFieldOptions{Pointer: "/n", Format: "%v"}, "3"},
+		{HookFieldOptions{Pointer: "/n", Format: "%d items"}, "3 items"},
+		{HookFieldOptions{Pointer: "/cost/total_cost_usd", Format: "$%.2f"}, "$0.42"},
+		{HookFieldOptions{Pointer: "/version", Format: "%.2f"}, "2.1.0"},
+		{HookFieldOptions{Pointer: "/dirs", Format: "%v"}, `["/a"]`},
+	}
+	for _, tt := range tests {
+		seg := HookField("custom", data, tt.opts, theme)
+		if !seg.Enabled || seg.Name != "custom" {
+			t.Errorf("%s: expected enabled segment named after the instance, got %+v", tt.opts.Pointer, seg)
+		}
+		if seg.Text != tt.want {
+			t.Errorf("%s with %q: expected %q, got %q", tt.opts.Pointer, tt.opts.Format, tt.want, seg.Text)
+		}
+	}
+}
+
+func TestHookFieldMissing(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	data := hookData(t, `{"version": null}`)
+
+	for _, pointer := range []string{"/version", "/missing"} {
+		if seg := HookField("custom", data, HookFieldOptions{Pointer: pointer, Format: "%v"}, theme); seg.Enabled {
+			t.Errorf("%s: expected disabled segment, got %q", pointer, seg.Text)
+		}
+	}
+}
+
+func TestHookFieldThresholds(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	opts := HookFieldOptions{Pointer: "/n", Format: "%v", Thresholds: config.ThresholdConfig{Warning: 5, Critical: 10}}
+	levels := HookFieldOptions{Pointer: "/n", Format: "%v", Thresholds: config.ThresholdConfig{
+		Levels: []config.ThresholdLevel{{At: 3, Color: "weekly"}, {At: 8, Color: "critical"}},
+	}}
+
+	tests := []struct {
+		opts HookFieldOptions
+		n    string
+		want string
+	}{
+		{opts, "1", "hook_field"},
+		{opts, "5", "warning"},
+		{opts, "12", "critical"},
+		{levels, "5", "weekly"},
+		{levels, "8", "critical"},
+	}
+	for _, tt := range tests {
+		seg := HookField("custom", hookData(t, `{"n": `+tt.n+`}`), tt.opts, theme)
+		if seg.BG != theme.Segments[tt.want].BG {
+			t.Errorf("%+v, n=%s: expected %s colors, got BG %q", tt.opts.Thresholds, tt.n, tt.want, seg.BG)
+		}
+	}
+}
+
+func TestHookFieldOptionsValidate(t *testing.T) {
+	for _, opts := range []HookFieldOptions{
+		{Format: "%v"},
+		{Pointer: "version", Format: "%v"},
+		{Pointer: "/version", Format: "plain"},
+		{Pointer: "/n", Format: "%v", Thresholds: config.ThresholdConfig{Warning: -1}},
+		{Pointer: "/n", Format: "%v", Thresholds: config.ThresholdConfig{Levels: []config.ThresholdLevel{{At: 1}}}},
+	} {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+	if err := (HookFieldOptions{Pointer: "/version", Format: "%v"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
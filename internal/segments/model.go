@@ -1,6 +1,7 @@
 package segments
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/rbarcante/conductor-powerline/internal/themes"
@@ -18,16 +19,39 @@ var modelNames = map[string]string{
 	"claude-sonnet-3-5": "Sonnet 3.5",
 }
 
+// ModelOptions configures the model segment.
+type ModelOptions struct {
+	// Format is "name" for the friendly name (e.g. "Opus 4.6") or "id" for
+	// the raw model ID.
+	Format string `json:"format"`
+}
+
+// DefaultModelOptions returns the model segment defaults.
+func DefaultModelOptions() ModelOptions {
+	return ModelOptions{Format: "name"}
+}
+
+// Validate implements Options.
+func (o ModelOptions) Validate() error {
+	if o.Format != "name" && o.Format != "id" {
+		return fmt.Errorf("format must be \"name\" or \"id\", got %q", o.Format)
+	}
+	return nil
+}
+
 // Model returns a segment displaying the friendly name of the active Claude model.
 // Returns a disabled segment if the model ID is empty.
-func Model(modelID string, theme themes.Theme) Segment {
+func Model(modelID string, opts ModelOptions, theme themes.Theme) Segment {
 	colors := theme.Segments["model"]
 
 	if modelID == "" {
 		return Segment{Name: "model", Enabled: false}
 	}
 
-	name := resolveFriendlyName(modelID)
+	name := modelID
+	if opts.Format != "id" {
+		name = resolveFriendlyName(modelID)
+	}
 
 	return Segment{
 		Name:    "model",
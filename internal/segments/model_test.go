@@ -21,7 +21,7 @@ func TestModelKnownIDs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.modelID, func(t *testing.T) {
-			seg := Model(tt.modelID, theme)
+			seg := Model(tt.modelID, DefaultModelOptions(), theme)
 			if seg.Text != tt.expected {
 				t.Errorf("Model(%q) = %q, want %q", tt.modelID, seg.Text, tt.expected)
 			}
@@ -37,7 +37,7 @@ func TestModelKnownIDs(t *testing.T) {
 
 func TestModelEmptyID(t *testing.T) {
 	theme, _ := themes.Get("dark")
-	seg := Model("", theme)
+	seg := Model("", DefaultModelOptions(), theme)
 
 	if seg.Enabled {
 		t.Error("expected segment disabled for empty model ID")
@@ -46,7 +46,7 @@ func TestModelEmptyID(t *testing.T) {
 
 func TestModelUnknownID(t *testing.T) {
 	theme, _ := themes.Get("dark")
-	seg := Model("some-unknown-model", theme)
+	seg := Model("some-unknown-model", DefaultModelOptions(), theme)
 
 	if !seg.Enabled {
 		t.Error("expected segment enabled for unknown model")
@@ -55,3 +55,12 @@ func TestModelUnknownID(t *testing.T) {
 		t.Errorf("expected raw model ID as text, got %q", seg.Text)
 	}
 }
+
+func TestModelFormatID(t *testing.T) {
+	theme, _ := themes.Get("dark")
+
+	seg := Model("claude-opus-4-6", ModelOptions{Format: "id"}, theme)
+	if seg.Text != "claude-opus-4-6" {
+		t.Errorf("expected raw model ID, got %q", seg.Text)
+	}
+}
@@ -0,0 +1,71 @@
+package segments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Options is implemented by each segment's typed options struct. Validate
+// reports values that decode but make no sense, e.g. a negative length.
+type Options interface {
+	Validate() error
+}
+
+// DecodeOptions decodes the raw "options" object from a segment's config onto
+// defaults and validates the result, so missing options are errors when the
+// defaults alone are not valid. Unknown keys, mistyped values and failed
+// validation are errors, in which case defaults is returned unchanged.
+func DecodeOptions[T Options](raw map[string]any, defaults T) (T, error) {
+	if len(raw) == 0 {
+		return defaults, defaults.Validate()
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return defaults, err
+	}
+	opts := defaults
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&opts); err != nil {
+		return defaults, err
+	}
+	if err := opts.Validate(); err != nil {
+		return defaults, err
+	}
+	return opts, nil
+}
+
+// optionValidators maps segment names, and custom segment types, to a check
+// of their options. Segments missing here take no options.
+var optionValidators = map[string]func(map[string]any) error{
+	"diff":       validateWith(DefaultDiffOptions()),
+	"directory":  validateWith(DefaultDirectoryOptions()),
+	"git":        validateWith(DefaultGitOptions()),
+	"hook_field": validateWith(DefaultHookFieldOptions()),
+	"model":      validateWith(DefaultModelOptions()),
+	"remote":     validateWith(DefaultRemoteOptions()),
+	"session":    validateWith(DefaultSessionOptions()),
+	"tools":      validateWith(DefaultToolsOptions()),
+}
+
+func validateWith[T Options](defaults T) func(map[string]any) error {
+	return func(raw map[string]any) error {
+		_, err := DecodeOptions(raw, defaults)
+		return err
+	}
+}
+
+// ValidateOptions reports problems with the options configured for the
+// segment name, or for a custom segment of that type, so they can be
+// surfaced as config diagnostics.
+func ValidateOptions(name string, raw map[string]any) error {
+	validate, ok := optionValidators[name]
+	if !ok {
+		if len(raw) == 0 {
+			return nil
+		}
+		return fmt.Errorf("segment %q takes no options", name)
+	}
+	return validate(raw)
+}
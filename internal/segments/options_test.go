@@ -0,0 +1,64 @@
+package segments
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeOptionsOverlaysDefaults(t *testing.T) {
+	opts, err := DecodeOptions(map[string]any{"dirtyMarker": "±"}, DefaultGitOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.DirtyMarker != "±" {
+		t.Errorf("expected dirtyMarker '±', got %q", opts.DirtyMarker)
+	}
+}
+
+func TestDecodeOptionsEmptyReturnsDefaults(t *testing.T) {
+	opts, err := DecodeOptions(nil, DefaultModelOptions())
+	if err != nil || opts != DefaultModelOptions() {
+		t.Errorf("expected defaults, got %+v (%v)", opts, err)
+	}
+}
+
+func TestDecodeOptionsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]any
+		want string
+	}{
+		{"unknown key", map[string]any{"maxLen": 5}, "unknown field"},
+		{"wrong type", map[string]any{"maxLength": "five"}, "cannot unmarshal"},
+		{"validation", map[string]any{"maxLength": -1}, "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := DecodeOptions(tt.raw, DefaultDirectoryOptions())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+			if opts != DefaultDirectoryOptions() {
+				t.Errorf("expected defaults on error, got %+v", opts)
+			}
+		})
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	if err := ValidateOptions("model", map[string]any{"format": "id"}); err != nil {
+		t.Errorf("expected valid model options, got %v", err)
+	}
+	if err := ValidateOptions("model", map[string]any{"format": "short"}); err == nil {
+		t.Error("expected invalid format to fail")
+	}
+	if err := ValidateOptions("block", map[string]any{"x": 1}); err == nil {
+		t.Error("expected error for a segment without options")
+	}
+	if err := ValidateOptions("block", nil); err != nil {
+		t.Errorf("expected no options to be valid for built-in segments, got %v", err)
+	}
+	if err := ValidateOptions("hook_field", nil); err == nil {
+		t.Error("expected hook_field without a pointer to fail")
+	}
+}
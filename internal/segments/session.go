@@ -0,0 +1,107 @@
+package segments
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rbarcante/conductor-powerline/internal/hook"
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+)
+
+// sessionParts lists the parts the session segment can show, in display order.
+var sessionParts = []string{"cost", "duration", "api", "lines"}
+
+// SessionOptions configures the session segment.
+type SessionOptions struct {
+	// Format is the printf format for the cost, e.g. "€%.2f" or "%.3f USD".
+	Format string `json:"format"`
+	// Rate converts the USD cost reported by Claude Code before formatting.
+	Rate float64 `json:"rate"`
+	// Budget is the per-session spend in USD the thresholds are measured
+	// against. Zero disables budget coloring.
+	Budget float64 `json:"budget"`
+	// Show selects the parts to display: cost, duration, api, lines.
+	Show []string `json:"show"`
+}
+
+// DefaultSessionOptions returns the session segment defaults.
+func DefaultSessionOptions() SessionOptions {
+	return SessionOptions{Format: "$%.2f", Rate: 1, Show: slices.Clone(sessionParts)}
+}
+
+// Validate implements Options.
+func (o SessionOptions) Validate() error {
+	if out := fmt.Sprintf(o.Format, 1.0); !strings.Contains(o.Format, "%") || strings.Contains(out, "%!") {
+		return fmt.Errorf("format must contain one number verb such as %%.2f, got %q", o.Format)
+	}
+	if o.Rate <= 0 {
+		return errors.New("rate must be positive")
+	}
+	if o.Budget < 0 {
+		return errors.New("budget must not be negative")
+	}
+	for _, part := range o.Show {
+		if !slices.Contains(sessionParts, part) {
+			return fmt.Errorf("unknown part %q in show (available: %s)", part, strings.Join(sessionParts, ", "))
+		}
+	}
+	return nil
+}
+
+// Session returns a segment with the session's cost, wall time, API time and
+// lines changed, e.g. "$0.42 · 12m · api 48s · +156/-23". When a budget is set
+// its colors follow thresholds measured as a percentage of the budget.
+// Returns a disabled segment when Claude Code sent no cost data.
+func Session(cost *hook.Cost, opts SessionOptions, thresholds Thresholds, theme themes.Theme) Segment {
+	if cost == nil {
+		return Segment{Name: "session", Enabled: false}
+	}
+
+	colors := theme.Segments["session"]
+	if opts.Budget > 0 {
+		colors = thresholdColors(theme, thresholds, cost.TotalCostUSD/opts.Budget*100, "session")
+	}
+
+	var parts []string
+	for _, part := range sessionParts {
+		if !slices.Contains(opts.Show, part) {
+			continue
+		}
+		switch part {
+		case "cost":
+			parts = append(parts, fmt.Sprintf(opts.Format, cost.TotalCostUSD*opts.Rate))
+		case "duration":
+			parts = append(parts, formatElapsed(time.Duration(cost.TotalDurationMS)*time.Millisecond))
+		case "api":
+			parts = append(parts, "api "+formatElapsed(time.Duration(cost.TotalAPIDurationMS)*time.Millisecond))
+		case "lines":
+			parts = append(parts, fmt.Sprintf("+%d/-%d", cost.TotalLinesAdded, cost.TotalLinesRemoved))
+		}
+	}
+	if len(parts) == 0 {
+		return Segment{Name: "session", Enabled: false}
+	}
+
+	return Segment{
+		Name:    "session",
+		Text:    strings.Join(parts, " · "),
+		FG:      colors.FG,
+		BG:      colors.BG,
+		Enabled: true,
+	}
+}
+
+// formatElapsed formats a duration compactly: "45s", "12m", "1h05m".
+func formatElapsed(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
@@ -0,0 +1,129 @@
+package segments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rbarcante/conductor-powerline/internal/hook"
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+)
+
+func sampleCost() *hook.Cost {
+	return &hook.Cost{
+		TotalCostUSD:       0.4231,
+		TotalDurationMS:    754000,
+		TotalAPIDurationMS: 48000,
+		TotalLinesAdded:    156,
+		TotalLinesRemoved:  23,
+	}
+}
+
+func TestSessionDefault(t *testing.T) {
+	theme, _ := themes.Get("dark")
+
+	seg := Session(sampleCost(), DefaultSessionOptions(), defaultThresholds("session"), theme)
+	if !seg.Enabled {
+		t.Fatal("expected segment enabled")
+	}
+	if want := "$0.42 · 12m · api 48s · +156/-23"; seg.Text != want {
+		t.Errorf("expected %q, got %q", want, seg.Text)
+	}
+	if seg.BG != theme.Segments["session"].BG {
+		t.Errorf("expected session colors without a budget, got BG %q", seg.BG)
+	}
+}
+
+func TestSessionNoCost(t *testing.T) {
+	theme, _ := themes.Get("dark")
+
+	if seg := Session(nil, DefaultSessionOptions(), defaultThresholds("session"), theme); seg.Enabled {
+		t.Error("expected segment disabled without cost data")
+	}
+}
+
+func TestSessionFormatAndParts(t *testing.T) {
+	theme, _ := themes.Get("dark")
+
+	opts := SessionOptions{Format: "%.1f€", Rate: 2, Show: []string{"lines", "cost"}}
+	seg := Session(sampleCost(), opts, defaultThresholds("session"), theme)
+	if want := "0.8€ · +156/-23"; seg.Text != want {
+		t.Errorf("expected %q, got %q", want, seg.Text)
+	}
+}
+
+func TestSessionDecodedShowKeepsOrder(t *testing.T) {
+	theme, _ := themes.Get("dark")
+
+	// Decoding onto the defaults must not write through to the list of
+	// parts, which fixes the display order.
+	for range 2 {
+		opts, err := DecodeOptions(map[string]any{"show": []any{"api", "cost"}}, DefaultSessionOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		seg := Session(sampleCost(), opts, defaultThresholds("session"), theme)
+		if want := "$0.42 · api 48s"; seg.Text != want {
+			t.Errorf("expected %q, got %q", want, seg.Text)
+		}
+	}
+	if seg := Session(sampleCost(), DefaultSessionOptions(), defaultThresholds("session"), theme); seg.Text != "$0.42 · 12m · api 48s · +156/-23" {
+		t.Errorf("expected the defaults unchanged, got %q", seg.Text)
+	}
+}
+
+func TestSessionBudgetColors(t *testing.T) {
+	theme, _ := themes.Get("dark")
+
+	tests := []struct {
+		budget float64
+		want   string
+	}{
+		{1.00, "session"},  // 42%
+		{0.50, "warning"},  // 85%
+		{0.40, "critical"}, // 106%
+	}
+	for _, tt := range tests {
+		opts := DefaultSessionOptions()
+		opts.Budget = tt.budget
+		seg := Session(sampleCost(), opts, defaultThresholds("session"), theme)
+		if seg.BG != theme.Segments[tt.want].BG {
+			t.Errorf("budget %.2f: expected %s colors, got BG %q", tt.budget, tt.want, seg.BG)
+		}
+	}
+}
+
+func TestSessionOptionsValidate(t *testing.T) {
+	bad := []SessionOptions{
+		{Format: "cost", Rate: 1},
+		{Format: "%d", Rate: 1},
+		{Format: "$%.2f", Rate: 0},
+		{Format: "$%.2f", Rate: 1, Budget: -1},
+		{Format: "$%.2f", Rate: 1, Show: []string{"tokens"}},
+	}
+	for _, opts := range bad {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", opts)
+		}
+	}
+	if err := DefaultSessionOptions().Validate(); err != nil {
+		t.Errorf("expected defaults valid, got %v", err)
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := map[int64]string{
+		0:       "0s",
+		45000:   "45s",
+		754000:  "12m",
+		3900000: "1h05m",
+	}
+	for ms, want := range tests {
+		if got := formatElapsed(msDuration(ms)); got != want {
+			t.Errorf("formatElapsed(%dms) = %q, want %q", ms, got, want)
+		}
+	}
+}
+
+func msDuration(ms int64) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
@@ -1,14 +1,80 @@
 package segments
 
-// Usage threshold constants shared across block and weekly segments.
-// Color changes from normal → warning → critical as usage increases.
-const (
-	usageWarningThreshold  = 70.0 // percent — switches to warning colors
-	usageCriticalThreshold = 90.0 // percent — switches to critical colors
-)
+import (
+	"sort"
 
-// Context window threshold constants for the context segment.
-const (
-	contextWarningThreshold  = 50 // percent of context window used
-	contextCriticalThreshold = 80 // percent of context window used
+	"github.com/rbarcante/conductor-powerline/internal/config"
+	"github.com/rbarcante/conductor-powerline/internal/themes"
 )
+
+// ThresholdLevel is a single step on a threshold scale. Once a value reaches
+// At, the segment switches to the theme colors stored under Color.
+type ThresholdLevel struct {
+	At    float64
+	Color string
+}
+
+// Thresholds is a list of levels evaluated against a percentage.
+// The highest level reached wins; below the first level the segment keeps
+// its own colors.
+type Thresholds []ThresholdLevel
+
+// ThresholdsFrom converts a configured scale, from the top-level thresholds or
+// a segment's options, into levels.
+func ThresholdsFrom(c config.ThresholdConfig) Thresholds {
+	var t Thresholds
+	for _, l := range c.ResolvedLevels() {
+		t = append(t, ThresholdLevel{At: l.At, Color: l.Color})
+	}
+	return t
+}
+
+// Level returns how many levels value has reached: 0 when below every level,
+// len(t) when at or above the highest one. Levels are sorted by At first, so
+// callers may pass them in any order.
+func (t Thresholds) Level(value float64) int {
+	sorted := t.sorted()
+	level := 0
+	for i, l := range sorted {
+		if value >= l.At {
+			level = i + 1
+		}
+	}
+	return level
+}
+
+// ColorKey returns the theme key for value: the Color of the highest level
+// reached, or base when no level applies.
+func (t Thresholds) ColorKey(value float64, base string) string {
+	level := t.Level(value)
+	if level == 0 {
+		return base
+	}
+	return t.sorted()[level-1].Color
+}
+
+// sorted returns a copy of t ordered by ascending At.
+func (t Thresholds) sorted() Thresholds {
+	s := make(Thresholds, len(t))
+	copy(s, t)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].At < s[j].At })
+	return s
+}
+
+// WeeklyThresholds groups the scales used by the weekly segment: the overall
+// 7-day percentage plus the per-model Opus and Sonnet breakdowns.
+type WeeklyThresholds struct {
+	Weekly Thresholds
+	Opus   Thresholds
+	Sonnet Thresholds
+}
+
+// thresholdColors returns the theme colors for value on the given scale.
+// Falls back to the base segment colors when the theme has no entry for the
+// level's color key (e.g. a custom key the theme does not define).
+func thresholdColors(theme themes.Theme, t Thresholds, value float64, base string) themes.SegmentColors {
+	if c, ok := theme.Segments[t.ColorKey(value, base)]; ok {
+		return c
+	}
+	return theme.Segments[base]
+}
@@ -0,0 +1,139 @@
+package segments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rbarcante/conductor-powerline/internal/config"
+	"github.com/rbarcante/conductor-powerline/internal/oauth"
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+)
+
+// defaultThresholds returns the scale the default config sets for name.
+func defaultThresholds(name string) Thresholds {
+	return ThresholdsFrom(config.DefaultConfig().Thresholds[name])
+}
+
+// defaultWeeklyThresholds returns the default scales of the weekly segment.
+func defaultWeeklyThresholds() WeeklyThresholds {
+	return WeeklyThresholds{
+		Weekly: defaultThresholds("weekly"),
+		Opus:   defaultThresholds("opus"),
+		Sonnet: defaultThresholds("sonnet"),
+	}
+}
+
+func TestThresholdsLevel(t *testing.T) {
+	th := defaultThresholds("block")
+
+	tests := []struct {
+		value float64
+		want  int
+	}{
+		{0, 0},
+		{69.9, 0},
+		{70, 1},
+		{89, 1},
+		{90, 2},
+		{100, 2},
+	}
+	for _, tt := range tests {
+		if got := th.Level(tt.value); got != tt.want {
+			t.Errorf("Level(%v) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestThresholdsUnsortedLevels(t *testing.T) {
+	th := Thresholds{
+		{At: 90, Color: "critical"},
+		{At: 50, Color: "warning"},
+	}
+	if got := th.ColorKey(60, "block"); got != "warning" {
+		t.Errorf("ColorKey(60) = %q, want %q", got, "warning")
+	}
+	if got := th.ColorKey(95, "block"); got != "critical" {
+		t.Errorf("ColorKey(95) = %q, want %q", got, "critical")
+	}
+}
+
+func TestThresholdsEmpty(t *testing.T) {
+	var th Thresholds
+	if got := th.ColorKey(100, "block"); got != "block" {
+		t.Errorf("ColorKey with no levels = %q, want base %q", got, "block")
+	}
+}
+
+func TestBlockCustomThresholds(t *testing.T) {
+	theme, _ := themes.Get("dark")
+
+	data := &oauth.UsageData{
+		BlockPercentage: 55.0,
+		BlockResetTime:  time.Now().Add(3 * time.Hour),
+	}
+
+	seg := Block(data, time.Now(), Thresholds{{At: 50, Color: "warning"}, {At: 80, Color: "critical"}}, theme)
+	if seg.BG != theme.Segments["warning"].BG {
+		t.Errorf("expected warning BG at 55%% with 50%% threshold, got %q", seg.BG)
+	}
+}
+
+func TestBlockThreeLevels(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	th := Thresholds{
+		{At: 40, Color: "weekly"},
+		{At: 60, Color: "warning"},
+		{At: 80, Color: "critical"},
+	}
+
+	data := &oauth.UsageData{BlockPercentage: 45.0, BlockResetTime: time.Now().Add(time.Hour)}
+	seg := Block(data, time.Now(), th, theme)
+	if seg.BG != theme.Segments["weekly"].BG {
+		t.Errorf("expected first level colors at 45%%, got BG %q", seg.BG)
+	}
+}
+
+func TestBlockUnknownColorKeyFallsBack(t *testing.T) {
+	theme, _ := themes.Get("dark")
+
+	data := &oauth.UsageData{BlockPercentage: 95.0, BlockResetTime: time.Now().Add(time.Hour)}
+	seg := Block(data, time.Now(), Thresholds{{At: 90, Color: "no-such-key"}}, theme)
+	if seg.BG != theme.Segments["block"].BG {
+		t.Errorf("expected base block colors for unknown key, got BG %q", seg.BG)
+	}
+}
+
+func TestWeeklyOpusThresholdWins(t *testing.T) {
+	theme, _ := themes.Get("dark")
+
+	data := &oauth.UsageData{
+		WeeklyPercentage: 40.0,
+		OpusPercentage:   60.0,
+		SonnetPercentage: 10.0,
+		WeekResetTime:    time.Now().Add(48 * time.Hour),
+	}
+	th := defaultWeeklyThresholds()
+	th.Opus = Thresholds{{At: 50, Color: "critical"}}
+
+	seg := Weekly(data, time.Now(), th, theme)
+	if seg.BG != theme.Segments["critical"].BG {
+		t.Errorf("expected critical BG from opus threshold, got %q", seg.BG)
+	}
+}
+
+func TestContextCustomThresholds(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	th := Thresholds{{At: 30, Color: "warning"}, {At: 60, Color: "critical"}}
+
+	seg := Context(40, true, th, theme)
+	if seg.Text != "◐ 40%" {
+		t.Errorf("Text = %q, want %q", seg.Text, "◐ 40%")
+	}
+	seg = Context(60, true, th, theme)
+	if seg.Text != "● 60%" {
+		t.Errorf("Text = %q, want %q", seg.Text, "● 60%")
+	}
+	if seg.BG != theme.Segments["critical"].BG {
+		t.Errorf("BG = %q, want critical", seg.BG)
+	}
+}
@@ -0,0 +1,128 @@
+package segments
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+	"github.com/rbarcante/conductor-powerline/internal/transcript"
+)
+
+// ToolsOptions configures the tools segment.
+type ToolsOptions struct {
+	// Top is how many of the most used tools are listed after the total.
+	Top int `json:"top"`
+}
+
+// DefaultToolsOptions returns the tools segment defaults.
+func DefaultToolsOptions() ToolsOptions {
+	return ToolsOptions{Top: 3}
+}
+
+// Validate implements Options.
+func (o ToolsOptions) Validate() error {
+	if o.Top < 0 {
+		return errors.New("top must not be negative")
+	}
+	return nil
+}
+
+// Turns returns a segment with the number of prompts and messages in the
+// session, e.g. "💬 4 turns · 37 msgs".
+func Turns(stats *transcript.Stats, theme themes.Theme) Segment {
+	if stats == nil || stats.Messages == 0 {
+		return Segment{Name: "turns", Enabled: false}
+	}
+	return transcriptSegment("turns", fmt.Sprintf("💬 %d %s · %d msgs", stats.Turns, plural(stats.Turns, "turn"), stats.Messages), theme)
+}
+
+// Tools returns a segment with the total tool calls followed by the most used
+// tools, e.g. "🔧 21 · Bash 9 Edit 6 Read 4".
+func Tools(stats *transcript.Stats, opts ToolsOptions, theme themes.Theme) Segment {
+	if stats == nil || len(stats.ToolCalls) == 0 {
+		return Segment{Name: "tools", Enabled: false}
+	}
+
+	names := make([]string, 0, len(stats.ToolCalls))
+	for name := range stats.ToolCalls {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ci, cj := stats.ToolCalls[names[i]], stats.ToolCalls[names[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > opts.Top {
+		names = names[:opts.Top]
+	}
+
+	text := fmt.Sprintf("🔧 %d", stats.TotalToolCalls())
+	if len(names) > 0 {
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = fmt.Sprintf("%s %d", name, stats.ToolCalls[name])
+		}
+		text += " · " + strings.Join(parts, " ")
+	}
+	return transcriptSegment("tools", text, theme)
+}
+
+// LastTool returns a segment with the name of the most recent tool call.
+func LastTool(stats *transcript.Stats, theme themes.Theme) Segment {
+	if stats == nil || stats.LastTool == "" {
+		return Segment{Name: "last_tool", Enabled: false}
+	}
+	return transcriptSegment("last_tool", "↳ "+stats.LastTool, theme)
+}
+
+// FilesEdited returns a segment with the number of distinct files changed by
+// edit tools, e.g. "✎ 5 files".
+func FilesEdited(stats *transcript.Stats, theme themes.Theme) Segment {
+	if stats == nil || len(stats.FilesEdited) == 0 {
+		return Segment{Name: "files_edited", Enabled: false}
+	}
+	n := len(stats.FilesEdited)
+	return transcriptSegment("files_edited", fmt.Sprintf("✎ %d %s", n, plural(n, "file")), theme)
+}
+
+// Tokens returns a segment with the session's token totals, e.g.
+// "↑12.4k ↓3.1k ⟳1.2M" for input, output and cache (created plus read).
+func Tokens(stats *transcript.Stats, theme themes.Theme) Segment {
+	if stats == nil || stats.Tokens == (transcript.Tokens{}) {
+		return Segment{Name: "tokens", Enabled: false}
+	}
+	t := stats.Tokens
+	text := fmt.Sprintf("↑%s ↓%s", formatCount(t.Input), formatCount(t.Output))
+	if cache := t.CacheCreation + t.CacheRead; cache > 0 {
+		text += " ⟳" + formatCount(cache)
+	}
+	return transcriptSegment("tokens", text, theme)
+}
+
+func transcriptSegment(name, text string, theme themes.Theme) Segment {
+	colors := theme.Segments["transcript"]
+	return Segment{Name: name, Text: text, FG: colors.FG, BG: colors.BG, Enabled: true}
+}
+
+// formatCount abbreviates large counts: 950, 12.4k, 1.2M.
+func formatCount(n int) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprintf("%d", n)
+	case n < 1000000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	}
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
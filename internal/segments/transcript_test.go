@@ -0,0 +1,75 @@
+package segments
+
+import (
+	"testing"
+
+	"github.com/rbarcante/conductor-powerline/internal/themes"
+	"github.com/rbarcante/conductor-powerline/internal/transcript"
+)
+
+func sampleStats() *transcript.Stats {
+	return &transcript.Stats{
+		Messages:    37,
+		Turns:       4,
+		ToolCalls:   map[string]int{"Bash": 9, "Edit": 6, "Read": 4, "Grep": 4},
+		LastTool:    "Edit",
+		FilesEdited: []string{"a.go", "b.go"},
+		Tokens:      transcript.Tokens{Input: 12400, Output: 3100, CacheCreation: 200000, CacheRead: 1000000},
+	}
+}
+
+func TestTranscriptSegments(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	stats := sampleStats()
+
+	tests := []struct {
+		seg  Segment
+		want string
+	}{
+		{Turns(stats, theme), "💬 4 turns · 37 msgs"},
+		{Tools(stats, DefaultToolsOptions(), theme), "🔧 23 · Bash 9 Edit 6 Grep 4"},
+		{Tools(stats, ToolsOptions{Top: 0}, theme), "🔧 23"},
+		{LastTool(stats, theme), "↳ Edit"},
+		{FilesEdited(stats, theme), "✎ 2 files"},
+		{Tokens(stats, theme), "↑12.4k ↓3.1k ⟳1.2M"},
+	}
+	for _, tt := range tests {
+		if !tt.seg.Enabled {
+			t.Errorf("%s: expected enabled", tt.seg.Name)
+		}
+		if tt.seg.Text != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.seg.Name, tt.want, tt.seg.Text)
+		}
+		if tt.seg.BG != theme.Segments["transcript"].BG {
+			t.Errorf("%s: expected transcript colors, got BG %q", tt.seg.Name, tt.seg.BG)
+		}
+	}
+}
+
+func TestTranscriptSegmentsWithoutStats(t *testing.T) {
+	theme, _ := themes.Get("dark")
+	empty := &transcript.Stats{}
+
+	for _, seg := range []Segment{
+		Turns(nil, theme), Tools(nil, DefaultToolsOptions(), theme), LastTool(nil, theme), FilesEdited(nil, theme), Tokens(nil, theme),
+		Turns(empty, theme), Tools(empty, DefaultToolsOptions(), theme), LastTool(empty, theme), FilesEdited(empty, theme), Tokens(empty, theme),
+	} {
+		if seg.Enabled {
+			t.Errorf("%s: expected disabled without transcript data", seg.Name)
+		}
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 999: "999", 1000: "1.0k", 12400: "12.4k", 2500000: "2.5M"} {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestToolsOptionsValidate(t *testing.T) {
+	if err := (ToolsOptions{Top: -1}).Validate(); err == nil {
+		t.Error("expected error for negative top")
+	}
+}
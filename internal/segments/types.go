@@ -5,8 +5,17 @@ package segments
 type Segment struct {
 	Name    string
 	Text    string
+	Spans   []Span // Optional: Text split into styled runs; their texts concatenate to Text.
 	Link    string // Optional: URL for OSC 8 hyperlink wrapping the entire segment.
 	FG      string
 	BG      string
 	Enabled bool
 }
+
+// Span is a run of segment text drawn in its own foreground color, or
+// linked on its own.
+type Span struct {
+	Text string
+	FG   string // Optional: overrides the segment FG for this run.
+	Link string // Optional: URL for an OSC 8 hyperlink on this run only; leave Segment.Link empty.
+}
@@ -9,8 +9,9 @@ import (
 )
 
 // Weekly returns a segment displaying the 7-day rolling usage with optional Opus/Sonnet breakdown.
-// Color intensity changes based on usage thresholds, matching Block() behavior.
-func Weekly(data *oauth.UsageData, theme themes.Theme) Segment {
+// Color intensity follows the most severe level reached on the weekly, Opus or
+// Sonnet scale, matching Block() behavior. Days left are counted from now.
+func Weekly(data *oauth.UsageData, now time.Time, thresholds WeeklyThresholds, theme themes.Theme) Segment {
 	colors := theme.Segments["weekly"]
 
 	if data == nil {
@@ -23,13 +24,7 @@ func Weekly(data *oauth.UsageData, theme themes.Theme) Segment {
 		}
 	}
 
-	// Select color based on usage threshold
-	switch {
-	case data.WeeklyPercentage >= usageCriticalThreshold:
-		colors = theme.Segments["critical"]
-	case data.WeeklyPercentage >= usageWarningThreshold:
-		colors = theme.Segments["warning"]
-	}
+	colors = weeklyColors(data, thresholds, theme)
 
 	var text string
 
@@ -41,7 +36,7 @@ func Weekly(data *oauth.UsageData, theme themes.Theme) Segment {
 	}
 
 	// Add week progress indicator
-	daysLeft := int(time.Until(data.WeekResetTime).Hours() / 24)
+	daysLeft := int(data.WeekResetTime.Sub(now).Hours() / 24)
 	if daysLeft > 0 {
 		text += fmt.Sprintf(" %dd", daysLeft)
 	}
@@ -58,3 +53,17 @@ func Weekly(data *oauth.UsageData, theme themes.Theme) Segment {
 		Enabled: true,
 	}
 }
+
+// weeklyColors picks the scale with the highest level reached and returns its
+// colors. Ties keep the earlier scale (weekly, then Opus, then Sonnet).
+func weeklyColors(data *oauth.UsageData, thresholds WeeklyThresholds, theme themes.Theme) themes.SegmentColors {
+	scale, value := thresholds.Weekly, data.WeeklyPercentage
+	best := scale.Level(value)
+	if l := thresholds.Opus.Level(data.OpusPercentage); l > best {
+		scale, value, best = thresholds.Opus, data.OpusPercentage, l
+	}
+	if l := thresholds.Sonnet.Level(data.SonnetPercentage); l > best {
+		scale, value = thresholds.Sonnet, data.SonnetPercentage
+	}
+	return thresholdColors(theme, scale, value, "weekly")
+}
@@ -17,7 +17,7 @@ func TestWeeklyPercentageDisplay(t *testing.T) {
 		WeekResetTime:    time.Now().Add(72 * time.Hour),
 	}
 
-	seg := Weekly(data, theme)
+	seg := Weekly(data, time.Now(), defaultWeeklyThresholds(), theme)
 	if !seg.Enabled {
 		t.Error("expected segment enabled")
 	}
@@ -39,7 +39,7 @@ func TestWeeklyOpusSonnetBreakdown(t *testing.T) {
 		WeekResetTime:    time.Now().Add(48 * time.Hour),
 	}
 
-	seg := Weekly(data, theme)
+	seg := Weekly(data, time.Now(), defaultWeeklyThresholds(), theme)
 	// Should show breakdown when both are in use
 	if !strings.Contains(seg.Text, "O:45%") {
 		t.Errorf("expected Opus breakdown in text, got %q", seg.Text)
@@ -59,7 +59,7 @@ func TestWeeklyNoBreakdownSingleModel(t *testing.T) {
 		WeekResetTime:    time.Now().Add(96 * time.Hour),
 	}
 
-	seg := Weekly(data, theme)
+	seg := Weekly(data, time.Now(), defaultWeeklyThresholds(), theme)
 	// Should not show breakdown when only one model is used
 	if strings.Contains(seg.Text, "S:") {
 		t.Errorf("expected no Sonnet breakdown for single model, got %q", seg.Text)
@@ -69,7 +69,7 @@ func TestWeeklyNoBreakdownSingleModel(t *testing.T) {
 func TestWeeklyNilData(t *testing.T) {
 	theme, _ := themes.Get("dark")
 
-	seg := Weekly(nil, theme)
+	seg := Weekly(nil, time.Now(), defaultWeeklyThresholds(), theme)
 	if !seg.Enabled {
 		t.Error("expected segment enabled with placeholder")
 	}
@@ -87,7 +87,7 @@ func TestWeeklyStaleData(t *testing.T) {
 		IsStale:          true,
 	}
 
-	seg := Weekly(data, theme)
+	seg := Weekly(data, time.Now(), defaultWeeklyThresholds(), theme)
 	if !strings.Contains(seg.Text, "~") {
 		t.Errorf("expected stale indicator '~' in text, got %q", seg.Text)
 	}
@@ -101,7 +101,7 @@ func TestWeeklyWeekProgress(t *testing.T) {
 		WeekResetTime:    time.Now().Add(4*24*time.Hour + 12*time.Hour),
 	}
 
-	seg := Weekly(data, theme)
+	seg := Weekly(data, time.Now(), defaultWeeklyThresholds(), theme)
 	// 4d12h from now truncates to 4 days
 	if !strings.Contains(seg.Text, "4d") {
 		t.Errorf("expected '4d' day indicator in text, got %q", seg.Text)
@@ -116,7 +116,7 @@ func TestWeeklyColorThresholdNormal(t *testing.T) {
 		WeekResetTime:    time.Now().Add(5 * 24 * time.Hour),
 	}
 
-	seg := Weekly(data, theme)
+	seg := Weekly(data, time.Now(), defaultWeeklyThresholds(), theme)
 	expectedColors := theme.Segments["weekly"]
 	if seg.BG != expectedColors.BG {
 		t.Errorf("expected normal BG %q, got %q", expectedColors.BG, seg.BG)
@@ -131,7 +131,7 @@ func TestWeeklyColorThresholdWarning(t *testing.T) {
 		WeekResetTime:    time.Now().Add(3 * 24 * time.Hour),
 	}
 
-	seg := Weekly(data, theme)
+	seg := Weekly(data, time.Now(), defaultWeeklyThresholds(), theme)
 	expectedColors := theme.Segments["warning"]
 	if seg.BG != expectedColors.BG {
 		t.Errorf("expected warning BG %q, got %q", expectedColors.BG, seg.BG)
@@ -146,7 +146,7 @@ func TestWeeklyColorThresholdCritical(t *testing.T) {
 		WeekResetTime:    time.Now().Add(1 * 24 * time.Hour),
 	}
 
-	seg := Weekly(data, theme)
+	seg := Weekly(data, time.Now(), defaultWeeklyThresholds(), theme)
 	expectedColors := theme.Segments["critical"]
 	if seg.BG != expectedColors.BG {
 		t.Errorf("expected critical BG %q, got %q", expectedColors.BG, seg.BG)
@@ -4,11 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"time"
 
+	"github.com/rbarcante/conductor-powerline/internal/claudehome"
 	"github.com/rbarcante/conductor-powerline/internal/debug"
 )
 
@@ -57,16 +57,14 @@ var execCommandFunc = func(ctx context.Context, name string, args ...string) *ex
 }
 
 // FindConductorCLI locates conductor_cli.py in the Claude plugin cache directory.
-// homeDir is the user's home directory; pass "" to use os.UserHomeDir().
+// homeDir is the user's home directory; pass "" to use the Claude config
+// directory ($CLAUDE_CONFIG_DIR or ~/.claude).
 func FindConductorCLI(homeDir string) string {
-	if homeDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return ""
-		}
-		homeDir = home
+	claudeDir := claudehome.Resolve(homeDir)
+	if claudeDir == "" {
+		return ""
 	}
-	pattern := filepath.Join(homeDir, ".claude", "plugins", "cache", "claude-conductor", "conductor", "*", "scripts", "conductor_cli.py")
+	pattern := filepath.Join(claudeDir, "plugins", "cache", "claude-conductor", "conductor", "*", "scripts", "conductor_cli.py")
 	matches, err := filepath.Glob(pattern)
 	if err != nil || len(matches) == 0 {
 		debug.Logf("workflow_cli", "conductor_cli.py not found at pattern %s", pattern)
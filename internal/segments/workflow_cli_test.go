@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -169,6 +170,16 @@ func TestFindConductorCLIFound(t *testing.T) {
 }
 
 // makeFakeCLI creates a fake conductor_cli.py in a temp home dir and returns the home path.
+func TestFindConductorCLIHonorsClaudeConfigDir(t *testing.T) {
+	fakeHome := makeFakeCLI(t)
+	t.Setenv("CLAUDE_CONFIG_DIR", filepath.Join(fakeHome, ".claude"))
+	t.Setenv("HOME", t.TempDir())
+
+	if got := FindConductorCLI(""); !strings.HasPrefix(got, fakeHome) {
+		t.Errorf("expected CLI under CLAUDE_CONFIG_DIR, got %q", got)
+	}
+}
+
 func makeFakeCLI(t *testing.T) string {
 	t.Helper()
 	home := t.TempDir()
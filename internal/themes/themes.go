@@ -37,6 +37,11 @@ var registry = map[string]Theme{
 			"workflow_track":    {FG: "214", BG: "237"}, // #ffaf00 / #404040
 			"workflow_tasks":    {FG: "117", BG: "236"}, // #87d7ff / #2d2d2d
 			"workflow_overall":  {FG: "183", BG: "235"}, // #d7afff / #2a2a2a
+			"session":           {FG: "229", BG: "236"}, // #ffffaf / #2d2d2d
+			"transcript":        {FG: "152", BG: "237"}, // #afd7d7 / #3a3a3a
+			"hook_field":        {FG: "255", BG: "238"}, // #eeeeee / #444444
+			"remote":            {FG: "117", BG: "238"}, // #87d7ff / #444444
+			"diff":              {FG: "186", BG: "238"}, // #d7d787 / #444444
 		},
 	},
 	"light": {
@@ -58,6 +63,11 @@ var registry = map[string]Theme{
 			"workflow_track":    {FG: "231", BG: "208"}, // #ffffff / #ff8700
 			"workflow_tasks":    {FG: "231", BG: "39"},  // #ffffff / #00afff
 			"workflow_overall":  {FG: "231", BG: "141"}, // #ffffff / #8b5cf6
+			"session":           {FG: "231", BG: "71"},  // #ffffff / #5faf5f
+			"transcript":        {FG: "231", BG: "67"},  // #ffffff / #5f87af
+			"hook_field":        {FG: "231", BG: "102"}, // #ffffff / #878787
+			"remote":            {FG: "231", BG: "74"},  // #ffffff / #5fafd7
+			"diff":              {FG: "231", BG: "137"}, // #ffffff / #af875f
 		},
 	},
 	"nord": {
@@ -79,6 +89,11 @@ var registry = map[string]Theme{
 			"workflow_track":    {FG: "180", BG: "60"},  // #d08770 / #434c5e
 			"workflow_tasks":    {FG: "152", BG: "59"},  // #8fbcbb / #2e3440
 			"workflow_overall":  {FG: "181", BG: "59"},  // #b48ead / #2e3440
+			"session":           {FG: "150", BG: "59"},  // #a3be8c / #3b4252
+			"transcript":        {FG: "110", BG: "59"},  // #88c0d0 / #434c5e
+			"hook_field":        {FG: "188", BG: "60"},  // #d8dee9 / #4c566a
+			"remote":            {FG: "110", BG: "60"},  // #81a1c1 / #4c566a
+			"diff":              {FG: "180", BG: "60"},  // #ebcb8b / #4c566a
 		},
 	},
 	"gruvbox": {
@@ -100,6 +115,11 @@ var registry = map[string]Theme{
 			"workflow_track":    {FG: "221", BG: "59"},  // #fabd2f / #3c3836
 			"workflow_tasks":    {FG: "145", BG: "235"}, // #83a598 / #282828
 			"workflow_overall":  {FG: "181", BG: "235"}, // #d3869b / #282828
+			"session":           {FG: "142", BG: "237"}, // #b8bb26 / #3c3836
+			"transcript":        {FG: "108", BG: "237"}, // #8ec07c / #3c3836
+			"hook_field":        {FG: "223", BG: "239"}, // #ebdbb2 / #504945
+			"remote":            {FG: "109", BG: "239"}, // #83a598 / #504945
+			"diff":              {FG: "175", BG: "239"}, // #d3869b / #504945
 		},
 	},
 	"tokyo-night": {
@@ -121,6 +141,11 @@ var registry = map[string]Theme{
 			"workflow_track":    {FG: "222", BG: "23"}, // #e0af68 / #191b29
 			"workflow_tasks":    {FG: "117", BG: "59"}, // #7dcfff / #1a202c
 			"workflow_overall":  {FG: "183", BG: "59"}, // #bb9af7 / #1a202c
+			"session":           {FG: "149", BG: "59"}, // #9ece6a / #2d3748
+			"transcript":        {FG: "117", BG: "59"}, // #7dcfff / #2d3748
+			"hook_field":        {FG: "189", BG: "60"}, // #c0caf5 / #414868
+			"remote":            {FG: "111", BG: "60"}, // #7aa2f7 / #414868
+			"diff":              {FG: "180", BG: "60"}, // #e0af68 / #414868
 		},
 	},
 	"rose-pine": {
@@ -142,6 +167,11 @@ var registry = map[string]Theme{
 			"workflow_track":    {FG: "222", BG: "17"}, // #f6c177 / #191724
 			"workflow_tasks":    {FG: "67", BG: "59"},  // #31748f / #232136
 			"workflow_overall":  {FG: "183", BG: "59"}, // #c4a7e7 / #232136
+			"session":           {FG: "223", BG: "59"}, // #f6c177 / #2a273f
+			"transcript":        {FG: "152", BG: "59"}, // #9ccfd8 / #2a273f
+			"hook_field":        {FG: "189", BG: "60"}, // #e0def4 / #393552
+			"remote":            {FG: "183", BG: "60"}, // #c4a7e7 / #393552
+			"diff":              {FG: "152", BG: "60"}, // #9ccfd8 / #393552
 		},
 	},
 }
@@ -24,6 +24,7 @@ func TestThemeSegmentColors(t *testing.T) {
 		"warning", "critical",
 		"conductor", "conductor_missing",
 		"workflow_setup", "workflow_track", "workflow_tasks", "workflow_overall",
+		"session", "transcript", "hook_field", "remote", "diff",
 	}
 
 	for _, name := range expectedThemes {
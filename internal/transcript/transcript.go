@@ -0,0 +1,268 @@
+// Package transcript summarizes a Claude Code session transcript (JSONL).
+// Reads are incremental: the byte offset reached and the running totals are
+// cached per session, so each render only parses lines appended since the
+// previous one.
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rbarcante/conductor-powerline/internal/atomicfile"
+)
+
+// Tokens holds token totals reported in assistant message usage.
+type Tokens struct {
+	Input         int `json:"input_tokens"`
+	Output        int `json:"output_tokens"`
+	CacheCreation int `json:"cache_creation_input_tokens"`
+	CacheRead     int `json:"cache_read_input_tokens"`
+}
+
+func (t Tokens) add(o Tokens) Tokens {
+	return Tokens{t.Input + o.Input, t.Output + o.Output, t.CacheCreation + o.CacheCreation, t.CacheRead + o.CacheRead}
+}
+
+func (t Tokens) sub(o Tokens) Tokens {
+	return Tokens{t.Input - o.Input, t.Output - o.Output, t.CacheCreation - o.CacheCreation, t.CacheRead - o.CacheRead}
+}
+
+// Stats are the session totals derived from the transcript.
+type Stats struct {
+	Messages    int            `json:"messages"`    // user and assistant messages
+	Turns       int            `json:"turns"`       // prompts typed by the user
+	ToolCalls   map[string]int `json:"toolCalls"`   // tool_use count by tool name
+	LastTool    string         `json:"lastTool"`    // name of the most recent tool_use
+	FilesEdited []string       `json:"filesEdited"` // sorted, distinct paths passed to editing tools
+	Tokens      Tokens         `json:"tokens"`
+}
+
+// TotalToolCalls returns the number of tool calls of any type.
+func (s Stats) TotalToolCalls() int {
+	n := 0
+	for _, c := range s.ToolCalls {
+		n += c
+	}
+	return n
+}
+
+// editTools maps tools that modify files to the input field naming the file.
+var editTools = map[string]string{
+	"Edit":         "file_path",
+	"MultiEdit":    "file_path",
+	"Write":        "file_path",
+	"NotebookEdit": "notebook_path",
+}
+
+// stateRetention is how long the state of a session outlives its last
+// render. A session resumed later is read from the start again.
+const stateRetention = 7 * 24 * time.Hour
+
+// state is what is cached between renders.
+type state struct {
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+	Stats  Stats  `json:"stats"`
+
+	// Claude Code writes one line per content block, each repeating the
+	// message's id and usage; a message is counted once, with the usage of
+	// its latest line.
+	LastMessageID string `json:"lastMessageId"`
+	LastUsage     Tokens `json:"lastUsage"`
+}
+
+// Read returns the stats for the transcript at path, resuming from the state
+// cached in cacheDir for sessionID. The cache is reset when the transcript
+// path changes or the file shrinks. An empty cacheDir disables caching.
+func Read(cacheDir, sessionID, path string) (*Stats, error) {
+	statePath := ""
+	if cacheDir != "" && sessionID != "" {
+		statePath = filepath.Join(cacheDir, "transcripts", safeName(sessionID)+".json")
+	}
+
+	st := loadState(statePath)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if st.Path != path || st.Offset > info.Size() {
+		st = state{Path: path}
+	}
+
+	if st.Offset < info.Size() {
+		consumed, err := st.consume(path)
+		if err != nil {
+			return nil, err
+		}
+		if consumed && statePath != "" {
+			_, statErr := os.Stat(statePath)
+			// A failed write only costs a full re-read on the next render.
+			_ = atomicfile.WriteJSON(statePath, st)
+			// Each session leaves a state file; a new one prunes those of
+			// sessions idle for stateRetention.
+			if os.IsNotExist(statErr) {
+				atomicfile.Prune(filepath.Dir(statePath), stateRetention)
+			}
+		}
+	}
+
+	stats := st.Stats
+	return &stats, nil
+}
+
+// consume parses complete lines after st.Offset. A trailing line without a
+// newline is still being written and is left for the next read.
+func (st *state) consume(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(st.Offset, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	r := bufio.NewReader(f)
+	consumed := false
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return consumed, err
+		}
+		st.Offset += int64(len(line))
+		st.apply(bytes.TrimSpace(line))
+		consumed = true
+	}
+	return consumed, nil
+}
+
+// entry is the subset of a transcript line we read.
+type entry struct {
+	Type        string `json:"type"`
+	IsMeta      bool   `json:"isMeta"`
+	IsSidechain bool   `json:"isSidechain"`
+	Message     struct {
+		ID      string          `json:"id"`
+		Content json.RawMessage `json:"content"`
+		Usage   *Tokens         `json:"usage"`
+	} `json:"message"`
+}
+
+type contentBlock struct {
+	Type  string         `json:"type"`
+	Name  string         `json:"name"`
+	Input map[string]any `json:"input"`
+}
+
+// apply folds one transcript line into the totals. Unparseable lines are
+// skipped.
+func (st *state) apply(line []byte) {
+	if len(line) == 0 {
+		return
+	}
+	var e entry
+	if err := json.Unmarshal(line, &e); err != nil {
+		return
+	}
+	if e.Type != "user" && e.Type != "assistant" {
+		return
+	}
+
+	s := &st.Stats
+	id := e.Message.ID
+	if id == "" || id != st.LastMessageID {
+		s.Messages++
+	}
+	if id != "" && id != st.LastMessageID {
+		st.LastMessageID, st.LastUsage = id, Tokens{}
+	}
+
+	var blocks []contentBlock
+	isText := false
+	if err := json.Unmarshal(e.Message.Content, &blocks); err != nil {
+		var text string
+		isText = json.Unmarshal(e.Message.Content, &text) == nil
+	}
+
+	switch e.Type {
+	case "user":
+		for _, b := range blocks {
+			if b.Type == "text" {
+				isText = true
+			}
+		}
+		if isText && !e.IsMeta && !e.IsSidechain {
+			s.Turns++
+		}
+	case "assistant":
+		for _, b := range blocks {
+			if b.Type != "tool_use" || b.Name == "" {
+				continue
+			}
+			if s.ToolCalls == nil {
+				s.ToolCalls = map[string]int{}
+			}
+			s.ToolCalls[b.Name]++
+			s.LastTool = b.Name
+			if field, ok := editTools[b.Name]; ok {
+				if file, _ := b.Input[field].(string); file != "" {
+					s.FilesEdited = addSorted(s.FilesEdited, file)
+				}
+			}
+		}
+		if u := e.Message.Usage; u != nil {
+			if id != "" {
+				s.Tokens = s.Tokens.sub(st.LastUsage)
+				st.LastUsage = *u
+			}
+			s.Tokens = s.Tokens.add(*u)
+		}
+	}
+}
+
+// addSorted inserts s into the sorted slice list unless already present.
+func addSorted(list []string, s string) []string {
+	i := sort.SearchStrings(list, s)
+	if i < len(list) && list[i] == s {
+		return list
+	}
+	list = append(list, "")
+	copy(list[i+1:], list[i:])
+	list[i] = s
+	return list
+}
+
+func loadState(path string) state {
+	var st state
+	if path == "" {
+		return st
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return st
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return state{}
+	}
+	return st
+}
+
+// safeName keeps session IDs from escaping the cache directory.
+func safeName(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '.' || r == ':' {
+			return '_'
+		}
+		return r
+	}, id)
+}
@@ -0,0 +1,146 @@
+package transcript
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const sample = `{"type":"user","message":{"role":"user","content":"fix the bug"}}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"text","text":"looking"}],"usage":{"input_tokens":10,"output_tokens":1,"cache_read_input_tokens":100}}}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","name":"Read","input":{"file_path":"a.go"}}],"usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":100}}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","content":"ok"}]}}
+{"type":"assistant","message":{"id":"m2","content":[{"type":"tool_use","name":"Edit","input":{"file_path":"a.go"}}],"usage":{"input_tokens":3,"output_tokens":7,"cache_creation_input_tokens":20}}}
+{"type":"system","content":"ignored"}
+`
+
+func writeTranscript(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func appendTranscript(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadStats(t *testing.T) {
+	path := writeTranscript(t, sample)
+
+	s, err := Read(t.TempDir(), "abc", path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Messages != 4 {
+		t.Errorf("expected 4 messages (m1 spans two lines), got %d", s.Messages)
+	}
+	if s.Turns != 1 {
+		t.Errorf("expected 1 turn (tool results are not prompts), got %d", s.Turns)
+	}
+	if s.ToolCalls["Read"] != 1 || s.ToolCalls["Edit"] != 1 || s.TotalToolCalls() != 2 {
+		t.Errorf("unexpected tool calls %v", s.ToolCalls)
+	}
+	if s.LastTool != "Edit" {
+		t.Errorf("expected last tool Edit, got %q", s.LastTool)
+	}
+	if len(s.FilesEdited) != 1 || s.FilesEdited[0] != "a.go" {
+		t.Errorf("expected a.go edited, got %v", s.FilesEdited)
+	}
+	want := Tokens{Input: 13, Output: 12, CacheCreation: 20, CacheRead: 100}
+	if s.Tokens != want {
+		t.Errorf("expected usage counted once per message %+v, got %+v", want, s.Tokens)
+	}
+}
+
+func TestReadIsIncremental(t *testing.T) {
+	cache := t.TempDir()
+	path := writeTranscript(t, sample)
+	if _, err := Read(cache, "abc", path); err != nil {
+		t.Fatal(err)
+	}
+
+	// A partial line is left for the next read.
+	appendTranscript(t, path, `{"type":"user","message":{"content":"again"}}`+"\n"+`{"type":"assistant","message":{"id":"m3"`)
+	s, err := Read(cache, "abc", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Turns != 2 || s.Messages != 5 {
+		t.Errorf("expected 2 turns / 5 messages, got %d / %d", s.Turns, s.Messages)
+	}
+
+	appendTranscript(t, path, `,"content":[{"type":"tool_use","name":"Write","input":{"file_path":"b.go"}}]}}`+"\n")
+	s, err = Read(cache, "abc", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.LastTool != "Write" || len(s.FilesEdited) != 2 || s.Messages != 6 {
+		t.Errorf("expected completed line applied once, got %+v", s)
+	}
+}
+
+func TestReadResetsWhenTranscriptShrinks(t *testing.T) {
+	cache := t.TempDir()
+	path := writeTranscript(t, sample)
+	if _, err := Read(cache, "abc", path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"type":"user","message":{"content":"new"}}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Read(cache, "abc", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Messages != 1 || s.TotalToolCalls() != 0 {
+		t.Errorf("expected state reset after truncation, got %+v", s)
+	}
+}
+
+func TestReadPrunesIdleSessions(t *testing.T) {
+	cache := t.TempDir()
+	path := writeTranscript(t, sample)
+	if _, err := Read(cache, "old", path); err != nil {
+		t.Fatal(err)
+	}
+	oldState := filepath.Join(cache, "transcripts", "old.json")
+	idle := time.Now().Add(-stateRetention - time.Hour)
+	if err := os.Chtimes(oldState, idle, idle); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Read(cache, "new", path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(oldState); !os.IsNotExist(err) {
+		t.Error("expected the idle session's state removed")
+	}
+	if _, err := os.Stat(filepath.Join(cache, "transcripts", "new.json")); err != nil {
+		t.Errorf("expected the new session's state kept: %v", err)
+	}
+}
+
+func TestReadMissingTranscript(t *testing.T) {
+	if _, err := Read(t.TempDir(), "abc", filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("expected error for missing transcript")
+	}
+}
+
+func TestSafeName(t *testing.T) {
+	if got := safeName("../etc/passwd"); got != "___etc_passwd" {
+		t.Errorf("expected path separators replaced, got %q", got)
+	}
+}
@@ -4,13 +4,21 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/rbarcante/conductor-powerline/internal/claudehome"
 	"github.com/rbarcante/conductor-powerline/internal/config"
 	"github.com/rbarcante/conductor-powerline/internal/debug"
 	"github.com/rbarcante/conductor-powerline/internal/hook"
@@ -18,6 +26,7 @@ import (
 	"github.com/rbarcante/conductor-powerline/internal/render"
 	"github.com/rbarcante/conductor-powerline/internal/segments"
 	"github.com/rbarcante/conductor-powerline/internal/themes"
+	"github.com/rbarcante/conductor-powerline/internal/transcript"
 )
 
 const anthropicUsageURL = "https://api.anthropic.com/api/oauth/usage"
@@ -26,9 +35,21 @@ const anthropicUsageURL = "https://api.anthropic.com/api/oauth/usage"
 // considered abandoned. Accounts for network jitter and OS scheduling delays.
 const lockStaleBuffer = 10 * time.Second
 
+// hookEnvVar holds hook JSON for runs outside Claude Code, e.g. tmux.
+const hookEnvVar = "CONDUCTOR_HOOK_JSON"
+
+// stdinTimeout bounds the wait for hook JSON on stdin. Claude Code writes it
+// immediately; a parent that never closes the pipe must not freeze the prompt.
+const stdinTimeout = 2 * time.Second
+
 func main() {
 	debug.Init()
-	if err := run(); err != nil {
+	if len(os.Args) > 1 {
+		if _, ok := commands[os.Args[1]]; ok {
+			os.Exit(runCommand(os.Args[1:], os.Stdout, os.Stderr))
+		}
+	}
+	if err := run(os.Args[1:]); err != nil {
 		debug.Logf("main", "run error: %v", err)
 		// Deliberate os.Exit(0) on error: a statusline tool must never return a
 		// non-zero exit code or produce stderr noise, as that would break the
@@ -37,49 +58,77 @@ func main() {
 	}
 }
 
-func run() error {
+func run(args []string) error {
 	debug.Logf("main", "starting conductor-powerline")
 
-	// 1. Parse stdin hook data
-	hookData, err := hook.Parse(os.Stdin)
+	// 0. Parse flag and environment overrides. Invalid values are reported as
+	// config diagnostics and skipped so a bad statusLine command still renders.
+	flags, flagErr := parseFlags(args)
+	if errors.Is(flagErr, flag.ErrHelp) {
+		return nil
+	}
+
+	// 1. Parse hook data from --hook-file, CONDUCTOR_HOOK_JSON or stdin
+	payload, err := readHookInput(flags.hookFile)
 	if err != nil {
 		return err
 	}
-	debug.Logf("main", "hook parsed: model=%s workspace=%s", hookData.ModelID(), hookData.WorkspacePath())
-
-	// 2. Load config (project → user → defaults)
-	// Prefer hookData.WorkspacePath() (explicit project from Claude Code hook JSON)
-	// with os.Getwd() as fallback for project config loading.
-	projectDir := hookData.WorkspacePath()
-	if projectDir == "" {
-		projectDir, _ = os.Getwd()
+	hookData, err := hook.Parse(bytes.NewReader(payload))
+	if err != nil {
+		return err
 	}
-	projectCfg := filepath.Join(projectDir, ".conductor-powerline.json")
-	debug.Logf("main", "project config path: %s", projectCfg)
-	userCfg := ""
-	if home, err := os.UserHomeDir(); err == nil {
-		userCfg = filepath.Join(home, ".claude", "conductor-powerline.json")
+	debug.Logf("main", "hook parsed: model=%s workspace=%s session=%s version=%s", hookData.ModelID(), hookData.WorkspacePath(), hookData.SessionID(), hookData.Version())
+
+	// 2-5. Resolve config and everything the segments display
+	snap := collectSnapshot(hookData, flags.config, flagErr)
+
+	// Save the inputs for the replay command when recording is enabled
+	if dir := os.Getenv(recordEnvVar); dir != "" {
+		if path, err := recordSnapshot(dir, snap); err != nil {
+			debug.Logf("record", "save failed: %v", err)
+		} else {
+			debug.Logf("record", "saved %s", path)
+		}
 	}
-	cfg := config.Load(projectCfg, userCfg)
-	debug.Logf("main", "config loaded: theme=%s segments=%v timeout=%v cacheTTL=%v", cfg.Theme, cfg.SegmentOrder, cfg.APITimeout.Duration, cfg.CacheTTL.Duration)
 
-	// 3. Resolve theme
-	theme, _ := themes.Get(cfg.Theme)
+	// 6-9. Build segments and render
+	fmt.Print(renderSnapshot(snap, hookData))
+	return nil
+}
 
-	// 4. Detect conductor status once (used for right segments and line 2 visibility)
-	// Prefer hookData.WorkspacePath() (explicit project from Claude Code hook JSON) with
-	// os.Getwd() as fallback for local config loading.
-	cwd, _ := os.Getwd()
+// collectSnapshot resolves the config and gathers every external input the
+// statusline renders from: usage, workflow status, git state and transcript
+// stats. Anything after this point is a pure function of the snapshot.
+func collectSnapshot(hookData hook.Data, flagCfg config.Config, flagErr error) snapshot {
+	snap := snapshot{
+		Version: snapshotVersion,
+		Now:     time.Now(),
+		Payload: hookData.Raw(),
+	}
+
+	// 2. Load config (flags → env → profiles → project files → user → defaults)
+	// Prefer hookData.WorkspacePath() (explicit project from Claude Code hook JSON)
+	// with os.Getwd() as fallback.
 	workspace := hookData.WorkspacePath()
 	if workspace == "" {
-		workspace = cwd
+		workspace, _ = os.Getwd()
 	}
-	conductorStatus := segments.DetectConductorStatus("", workspace)
-	debug.Logf("main", "conductor status: %d (workspace=%s)", conductorStatus, workspace)
+	currentDir := hookData.CurrentDir()
+	if currentDir == "" {
+		currentDir = workspace
+	}
+	cfg, diags := loadConfig(workspace, currentDir, hookData.ModelID(), flagCfg, flagErr)
+	for _, d := range diags {
+		debug.Logf("config", "%s", d)
+	}
+	debug.Logf("main", "config loaded: theme=%s segments=%v timeout=%v cacheTTL=%v", cfg.Theme, cfg.SegmentOrder, cfg.APITimeout.Duration, cfg.CacheTTL.Duration)
+	snap.Config, snap.Diagnostics = cfg, diags
 
-	// 5. Fetch usage data and workflow data concurrently
-	var usageData *oauth.UsageData
-	var workflowData *segments.WorkflowData
+	// 3. Detect conductor status once (used for right segments and line 2 visibility)
+	snap.ConductorStatus = segments.DetectConductorStatus("", workspace)
+	debug.Logf("main", "conductor status: %d (workspace=%s)", snap.ConductorStatus, workspace)
+
+	// 4. Fetch usage data and workflow data concurrently
 	var wg sync.WaitGroup
 
 	wg.Add(1)
@@ -91,24 +140,23 @@ func run() error {
 		lock := oauth.NewCacheLock(dir, cfg.APITimeout.Duration+lockStaleBuffer)
 		data, err := oauth.FetchUsage(client, cache, "global-usage", lock)
 		if err == nil {
-			usageData = data
+			snap.Usage = data
 		} else {
 			debug.Logf("main", "usage fetch failed: %v", err)
 		}
-		// On error, usageData remains nil → segments show "--" placeholder
+		// On error, Usage remains nil → segments show "--" placeholder
 	}()
 
 	// Fetch workflow data concurrently when conductor is active
-	if conductorStatus == segments.ConductorActive {
+	if snap.ConductorStatus == segments.ConductorActive {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			ctx, cancel := context.WithTimeout(context.Background(), cfg.APITimeout.Duration)
 			defer cancel()
-			home, _ := os.UserHomeDir()
-			data, err := segments.FetchWorkflowStatus(ctx, home, workspace)
+			data, err := segments.FetchWorkflowStatus(ctx, "", workspace)
 			if err == nil {
-				workflowData = data
+				snap.Workflow = data
 			} else {
 				debug.Logf("main", "workflow fetch failed: %v", err)
 			}
@@ -117,18 +165,52 @@ func run() error {
 
 	wg.Wait()
 
-	if usageData != nil {
-		debug.Logf("main", "usage data available: block=%.1f%% weekly=%.1f%% stale=%v", usageData.BlockPercentage, usageData.WeeklyPercentage, usageData.IsStale)
+	if snap.Usage != nil {
+		debug.Logf("main", "usage data available: block=%.1f%% weekly=%.1f%% stale=%v", snap.Usage.BlockPercentage, snap.Usage.WeeklyPercentage, snap.Usage.IsStale)
 	} else {
 		debug.Logf("main", "usage data is nil — segments will show '--'")
 	}
 
-	// 6. Build line 1 segments in configured order
-	segs := buildSegments(cfg, hookData, theme, usageData)
+	// 5. Read git state, the diff, the origin remote and the transcript only
+	// when a segment shows them.
+	// Git follows Claude into nested repositories and submodules.
+	if segmentShown(cfg, "git") {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.GitTimeout.Duration)
+		snap.Git = segments.ReadGitStatus(ctx, cacheDir(), hookData.CurrentDir())
+		cancel()
+	}
+	if segmentShown(cfg, "diff") {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.GitTimeout.Duration)
+		snap.Diff = segments.ReadDiff(ctx, cacheDir(), hookData.CurrentDir())
+		cancel()
+	}
+	if segmentShown(cfg, "remote") {
+		snap.Remote = segments.ReadRemote(hookData.CurrentDir())
+	}
+	if segmentShown(cfg, transcriptSegments...) {
+		snap.Transcript = readTranscript(hookData)
+	}
+
+	return snap
+}
+
+// renderSnapshot builds the segments from snap and renders the statusline.
+func renderSnapshot(snap snapshot, hookData hook.Data) string {
+	cfg := snap.Config
+
+	// 6. Resolve theme
+	theme, _ := themes.Get(cfg.Theme)
+
+	// 7. Build line 1 segments in configured order, led by a warning when
+	// the config had problems
+	segs := buildSegments(snap, hookData, theme)
+	if warn := segments.ConfigWarning(len(snap.Diagnostics), theme); warn.Enabled {
+		segs = append([]segments.Segment{warn}, segs...)
+	}
 	debug.Logf("main", "built %d segments", len(segs))
 
-	// 7. Build right-side segments (context window + conductor indicator)
-	rightSegs := buildRightSegments(cfg, conductorStatus, theme, hookData)
+	// Right-side segments (context window + conductor indicator)
+	rightSegs := buildRightSegments(cfg, snap.ConductorStatus, theme, hookData)
 	debug.Logf("main", "built %d right segments", len(rightSegs))
 
 	// 8. Render line 1
@@ -136,23 +218,140 @@ func run() error {
 	rightOutput := render.RenderRight(rightSegs, cfg.Display.NerdFontsEnabled())
 
 	// 9. Build and render line 2 (conductor workflow) when conditions are met
-	workflowEnabled := true
-	if wfCfg, ok := cfg.Segments["conductor_workflow"]; ok {
-		workflowEnabled = wfCfg.Enabled
-	}
+	workflowEnabled := cfg.Segments["conductor_workflow"].IsEnabled()
 	debug.Logf("main", "line2 conditions: conductorActive=%v workflowData=%v workflowEnabled=%v",
-		conductorStatus == segments.ConductorActive, workflowData != nil, workflowEnabled)
+		snap.ConductorStatus == segments.ConductorActive, snap.Workflow != nil, workflowEnabled)
 
-	if conductorStatus == segments.ConductorActive && workflowData != nil && workflowEnabled {
-		line2Segs := buildWorkflowSegments(workflowData, cfg, theme)
+	if snap.ConductorStatus == segments.ConductorActive && snap.Workflow != nil && workflowEnabled {
+		line2Segs := buildWorkflowSegments(snap.Workflow, cfg, theme)
 		debug.Logf("main", "built %d line2 workflow segments", len(line2Segs))
 		line2Output := render.Render(line2Segs, cfg.Display.NerdFontsEnabled(), cfg.Display.CompactWidth)
-		fmt.Print(output + rightOutput + "\n" + line2Output)
-	} else {
-		fmt.Print(output + rightOutput)
+		return output + rightOutput + "\n" + line2Output
 	}
+	return output + rightOutput
+}
 
-	return nil
+// loadConfig resolves the config for currentDir: the user file, project files
+// from the git root down to currentDir, matching profiles, then environment
+// and flag overrides. Trust is looked up for projectDir, the workspace the
+// user allowed. Problems, including invalid env or flag values, are returned
+// as diagnostics rather than errors.
+func loadConfig(projectDir, currentDir, modelID string, flagCfg config.Config, flagErr error) (config.Config, []config.Diagnostic) {
+	envCfg, envErr := config.FromEnv(os.Getenv)
+
+	projectCfgs := config.DiscoverProjectFiles(currentDir)
+	debug.Logf("main", "project config paths: %v", projectCfgs)
+	userCfg := ""
+	trusted := false
+	if dir := claudehome.Dir(); dir != "" {
+		userCfg = config.FindFile(dir, "conductor-powerline")
+		if len(projectCfgs) > 0 {
+			store, err := config.LoadTrust(filepath.Join(dir, config.TrustFileName))
+			if err != nil {
+				debug.Logf("main", "trust store error: %v", err)
+			}
+			trusted = store.Trusted(projectDir, projectCfgs)
+		}
+	}
+	debug.Logf("main", "project config trusted: %v", trusted)
+
+	// Branch and remote come from the directory Claude Code is in, which may
+	// be another repository below projectDir; they are only read if a
+	// profile matches on them.
+	cfg, diags := config.LoadSources(config.Sources{
+		UserPath:       userCfg,
+		ProjectPaths:   projectCfgs,
+		ProjectTrusted: trusted,
+		Match: &config.MatchContext{
+			Workspace: projectDir,
+			ModelID:   modelID,
+			Branch:    sync.OnceValue(func() string { return segments.GitBranch(currentDir) }),
+			RemoteURL: sync.OnceValue(func() string { return segments.GitRemoteURL(currentDir) }),
+			Getenv:    os.Getenv,
+		},
+		Overrides: []config.Config{envCfg, flagCfg},
+	})
+	for _, name := range sortedSegmentNames(cfg.Segments) {
+		seg := cfg.Segments[name]
+		kind := name
+		if seg.Type != "" {
+			if !slices.Contains(config.SegmentTypes, seg.Type) {
+				continue // reported by LoadSources
+			}
+			kind = seg.Type
+		}
+		if err := segments.ValidateOptions(kind, seg.Options); err != nil {
+			diags = append(diags, config.Diagnostic{Source: "config", Key: "segments." + name + ".options", Message: err.Error()})
+		}
+	}
+	diags = append(diags, errorDiagnostics("environment", envErr)...)
+	diags = append(diags, errorDiagnostics("flags", flagErr)...)
+	return cfg, diags
+}
+
+func sortedSegmentNames(m map[string]config.SegmentConfig) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// errorDiagnostics splits a (possibly joined) error into one diagnostic per
+// line.
+func errorDiagnostics(source string, err error) []config.Diagnostic {
+	if err == nil {
+		return nil
+	}
+	var diags []config.Diagnostic
+	for _, line := range strings.Split(err.Error(), "\n") {
+		diags = append(diags, config.Diagnostic{Source: source, Message: line})
+	}
+	return diags
+}
+
+// runFlags is the parsed statusline command line.
+type runFlags struct {
+	config   config.Config // overrides, applied after env overrides
+	hookFile string
+}
+
+// parseFlags parses command-line overrides. -h/--help prints usage to stdout
+// and returns flag.ErrHelp; other errors leave the flags parsed so far applied.
+func parseFlags(args []string) (runFlags, error) {
+	var flags runFlags
+	fs := flag.NewFlagSet("conductor-powerline", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	config.BindFlags(fs, &flags.config)
+	fs.StringVar(&flags.hookFile, "hook-file", "", "read hook JSON from `path` instead of stdin")
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fs.SetOutput(os.Stdout)
+		_, _ = fmt.Fprintf(os.Stdout, "Usage: conductor-powerline [flags]\n\nConfig flags can also be set via %s<NAME>, e.g. %s.\nHook JSON is read from --hook-file, then %s, then stdin.\n\n", config.EnvPrefix, config.EnvName("theme"), hookEnvVar)
+		fs.PrintDefaults()
+	}
+	return flags, err
+}
+
+// readHookInput returns the hook JSON from hookFile if set, then the
+// CONDUCTOR_HOOK_JSON variable, then stdin. An interactive or stalled stdin
+// yields no data rather than an error, so the statusline still renders.
+func readHookInput(hookFile string) ([]byte, error) {
+	if hookFile != "" {
+		debug.Logf("main", "reading hook data from %s", hookFile)
+		return os.ReadFile(hookFile)
+	}
+	if v := os.Getenv(hookEnvVar); v != "" {
+		debug.Logf("main", "reading hook data from %s", hookEnvVar)
+		return []byte(v), nil
+	}
+	data, err := hook.ReadStdin(os.Stdin, stdinTimeout)
+	if err != nil {
+		debug.Logf("main", "skipping stdin: %v", err)
+		return nil, nil
+	}
+	return data, nil
 }
 
 // rightSideSegments lists segment names that render on the right side of line 1.
@@ -166,22 +365,64 @@ var line2Segments = map[string]bool{
 	"conductor_workflow": true,
 }
 
-func buildSegments(cfg config.Config, hookData hook.Data, theme themes.Theme, usageData *oauth.UsageData) []segments.Segment {
+// transcriptSegments lists the segments built from the session transcript.
+var transcriptSegments = []string{"turns", "tools", "last_tool", "files_edited", "tokens"}
+
+// segmentShown reports whether any of names is enabled and in the order.
+func segmentShown(cfg config.Config, names ...string) bool {
+	for _, name := range cfg.SegmentOrder {
+		if cfg.Segments[name].IsEnabled() && slices.Contains(names, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildSegments(snap snapshot, hookData hook.Data, theme themes.Theme) []segments.Segment {
+	cfg, stats := snap.Config, snap.Transcript
 	builders := map[string]func() segments.Segment{
 		"directory": func() segments.Segment {
-			return segments.Directory(hookData.WorkspacePath(), theme)
+			return segments.Directory(hookData.WorkspacePath(), hookData.CurrentDir(), segmentOptions(cfg, "directory", segments.DefaultDirectoryOptions()), theme)
 		},
 		"git": func() segments.Segment {
-			return segments.Git(hookData.WorkspacePath(), theme)
+			return segments.Git(snap.Git, segmentOptions(cfg, "git", segments.DefaultGitOptions()), theme)
+		},
+		"remote": func() segments.Segment {
+			return segments.Remote(snap.Remote, segmentOptions(cfg, "remote", segments.DefaultRemoteOptions()), theme)
+		},
+		"diff": func() segments.Segment {
+			return segments.Diff(snap.Diff, segmentOptions(cfg, "diff", segments.DefaultDiffOptions()), segmentThresholds(cfg, "diff"), theme)
 		},
 		"model": func() segments.Segment {
-			return segments.Model(hookData.ModelID(), theme)
+			return segments.Model(hookData.ModelID(), segmentOptions(cfg, "model", segments.DefaultModelOptions()), theme)
 		},
 		"block": func() segments.Segment {
-			return segments.Block(usageData, theme)
+			return segments.Block(snap.Usage, snap.Now, segmentThresholds(cfg, "block"), theme)
+		},
+		"session": func() segments.Segment {
+			return segments.Session(hookData.Cost(), segmentOptions(cfg, "session", segments.DefaultSessionOptions()), segmentThresholds(cfg, "session"), theme)
+		},
+		"turns": func() segments.Segment {
+			return segments.Turns(stats, theme)
+		},
+		"tools": func() segments.Segment {
+			return segments.Tools(stats, segmentOptions(cfg, "tools", segments.DefaultToolsOptions()), theme)
+		},
+		"last_tool": func() segments.Segment {
+			return segments.LastTool(stats, theme)
+		},
+		"files_edited": func() segments.Segment {
+			return segments.FilesEdited(stats, theme)
+		},
+		"tokens": func() segments.Segment {
+			return segments.Tokens(stats, theme)
 		},
 		"weekly": func() segments.Segment {
-			return segments.Weekly(usageData, theme)
+			return segments.Weekly(snap.Usage, snap.Now, segments.WeeklyThresholds{
+				Weekly: segmentThresholds(cfg, "weekly"),
+				Opus:   segmentThresholds(cfg, "opus"),
+				Sonnet: segmentThresholds(cfg, "sonnet"),
+			}, theme)
 		},
 	}
 
@@ -190,11 +431,13 @@ func buildSegments(cfg config.Config, hookData hook.Data, theme themes.Theme, us
 		if rightSideSegments[name] || line2Segments[name] {
 			continue // Rendered separately
 		}
-		segCfg, hasCfg := cfg.Segments[name]
-		if hasCfg && !segCfg.Enabled {
+		if !cfg.Segments[name].IsEnabled() {
 			continue
 		}
 		builder, ok := builders[name]
+		if !ok {
+			builder, ok = customBuilder(cfg, name, hookData, theme)
+		}
 		if !ok {
 			continue
 		}
@@ -203,6 +446,52 @@ func buildSegments(cfg config.Config, hookData hook.Data, theme themes.Theme, us
 	return result
 }
 
+// customBuilder returns the builder for name when the config defines it as a
+// custom segment.
+func customBuilder(cfg config.Config, name string, hookData hook.Data, theme themes.Theme) (func() segments.Segment, bool) {
+	switch cfg.Segments[name].Type {
+	case "hook_field":
+		return func() segments.Segment {
+			return segments.HookField(name, hookData, segmentOptions(cfg, name, segments.DefaultHookFieldOptions()), theme)
+		}, true
+	}
+	return nil, false
+}
+
+// readTranscript summarizes the session transcript named in the hook data,
+// resuming from the offset cached for the session. Returns nil when there is
+// no transcript or it cannot be read.
+func readTranscript(hookData hook.Data) *transcript.Stats {
+	path := hookData.TranscriptPath()
+	if path == "" {
+		return nil
+	}
+	stats, err := transcript.Read(cacheDir(), hookData.SessionID(), path)
+	if err != nil {
+		debug.Logf("main", "transcript read failed: %v", err)
+		return nil
+	}
+	debug.Logf("main", "transcript: messages=%d tools=%d files=%d", stats.Messages, stats.TotalToolCalls(), len(stats.FilesEdited))
+	return stats
+}
+
+// segmentOptions decodes the configured options for segment name onto
+// defaults. Invalid options fall back to the defaults; they are reported as
+// config diagnostics by loadConfig.
+func segmentOptions[T segments.Options](cfg config.Config, name string, defaults T) T {
+	opts, err := segments.DecodeOptions(cfg.Segments[name].Options, defaults)
+	if err != nil {
+		debug.Logf("main", "%s options: %v", name, err)
+	}
+	return opts
+}
+
+// segmentThresholds converts the configured threshold scale for name into
+// the form segment builders consume.
+func segmentThresholds(cfg config.Config, name string) segments.Thresholds {
+	return segments.ThresholdsFrom(cfg.Thresholds[name])
+}
+
 // cacheDir returns the cache directory for conductor-powerline.
 // Uses $XDG_CACHE_HOME/conductor-powerline if set, otherwise ~/.cache/conductor-powerline.
 func cacheDir() string {
@@ -219,17 +508,15 @@ func buildRightSegments(cfg config.Config, conductorStatus segments.ConductorSta
 	var result []segments.Segment
 
 	// Context segment (leftmost right-side segment)
-	ctxCfg, hasCfg := cfg.Segments["context"]
-	if !hasCfg || ctxCfg.Enabled {
-		seg := segments.Context(hookData.ContextPercent(), cfg.Display.NerdFontsEnabled(), theme)
+	if cfg.Segments["context"].IsEnabled() {
+		seg := segments.Context(hookData.ContextPercent(), cfg.Display.NerdFontsEnabled(), segmentThresholds(cfg, "context"), theme)
 		if seg.Enabled {
 			result = append(result, seg)
 		}
 	}
 
 	// Conductor segment (rightmost — after context)
-	condCfg, hasCfg := cfg.Segments["conductor"]
-	if !hasCfg || condCfg.Enabled {
+	if cfg.Segments["conductor"].IsEnabled() {
 		seg := segments.Conductor(conductorStatus, cfg.Display.NerdFontsEnabled(), theme)
 		if seg.Enabled {
 			result = append(result, seg)
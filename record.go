@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rbarcante/conductor-powerline/internal/config"
+	"github.com/rbarcante/conductor-powerline/internal/hook"
+	"github.com/rbarcante/conductor-powerline/internal/oauth"
+	"github.com/rbarcante/conductor-powerline/internal/segments"
+	"github.com/rbarcante/conductor-powerline/internal/transcript"
+)
+
+// recordEnvVar names the directory each render's snapshot is saved to.
+const recordEnvVar = "CONDUCTOR_RECORD"
+
+// snapshotVersion is bumped when the snapshot format changes incompatibly.
+const snapshotVersion = 2
+
+// snapshot holds every input a render depends on, so a statusline can be
+// reproduced on another machine. Now is the clock countdowns are measured
+// from; replays reuse it so the output does not drift.
+type snapshot struct {
+	Version         int                      `json:"version"`
+	Now             time.Time                `json:"now"`
+	Payload         json.RawMessage          `json:"payload"`
+	Config          config.Config            `json:"config"`
+	Diagnostics     []config.Diagnostic      `json:"diagnostics,omitempty"`
+	ConductorStatus segments.ConductorStatus `json:"conductorStatus"`
+	Usage           *oauth.UsageData         `json:"usage,omitempty"`
+	Workflow        *segments.WorkflowData   `json:"workflow,omitempty"`
+	Git             *segments.GitStatus      `json:"git,omitempty"`
+	Remote          *segments.RemoteInfo     `json:"remote,omitempty"`
+	Diff            *segments.DiffStat       `json:"diff,omitempty"`
+	Transcript      *transcript.Stats        `json:"transcript,omitempty"`
+}
+
+// recordSnapshot writes snap to a new file in dir and returns its path.
+func recordSnapshot(dir string, snap snapshot) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	name := snap.Now.UTC().Format("20060102T150405.000000000")
+	var payload struct {
+		SessionID string `json:"session_id"`
+	}
+	if json.Unmarshal(snap.Payload, &payload) == nil && payload.SessionID != "" {
+		name += "-" + strings.NewReplacer("/", "_", `\`, "_", ".", "_").Replace(payload.SessionID)
+	}
+	path := filepath.Join(dir, name+".json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// loadSnapshot reads a snapshot written by recordSnapshot.
+func loadSnapshot(path string) (snapshot, error) {
+	var snap snapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snap, err
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return snap, fmt.Errorf("%s: %w", path, err)
+	}
+	if snap.Version != snapshotVersion {
+		return snap, fmt.Errorf("%s: unsupported snapshot version %d (want %d)", path, snap.Version, snapshotVersion)
+	}
+	return snap, nil
+}
+
+// replayCommand re-renders a recorded snapshot. Nothing is read from the
+// network, git, config files or the clock, so the output is the same on any
+// machine.
+func replayCommand(args []string, stdout, _ io.Writer) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: replay <file>")
+	}
+	snap, err := loadSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+	var hookData hook.Data
+	if len(snap.Payload) > 0 {
+		if err := json.Unmarshal(snap.Payload, &hookData); err != nil {
+			return fmt.Errorf("%s: payload: %w", args[0], err)
+		}
+	}
+	_, err = fmt.Fprint(stdout, renderSnapshot(snap, hookData))
+	return err
+}